@@ -0,0 +1,550 @@
+// Package arranger provides the built in model arranger.
+//
+// How it works:
+// 1. The footprint of each model is the convex hull of all its points projected onto the bed.
+//    So the models can't collide at any height, not only at the first layer.
+// 2. The models are sorted by the size of their footprint, the biggest first.
+// 3. Each model is placed at the first free position on the bed, searching row by row from the front left corner.
+//    A position is free if the footprint keeps at least the spacing to the footprints of all already placed models.
+//    This allows models to move into the gaps of the other ones, which is not possible when just using their bounding boxes.
+// 4. All models are dropped onto the bed and merged into one model.
+//    The optimizer moves this model to the center of the bed afterwards.
+//
+// If options.Arrange.SplitShells is enabled, each model is split into its disconnected shells before.
+// Faces belong to the same shell if they share a point. Like in the optimizer, points which are nearer than
+// the options.Slicing.MeldDistance are the same. Each shell is placed as separate model.
+//
+// If the bed size is not configured, the models are placed on a square which is just big enough for their footprints.
+// If a model does not fit anywhere on the bed a *DoesNotFitError is returned.
+
+package arranger
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/aligator/goslice/clip"
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/handler"
+)
+
+// gridStep is the distance between the positions which are checked for each model.
+const gridStep = data.Micrometer(1000)
+
+type arranger struct {
+	options *data.Options
+}
+
+// NewArranger provides a model arranger which uses the options.Arrange and the bed size of the options.Printer.
+func NewArranger(options *data.Options) handler.ModelArranger {
+	return &arranger{
+		options: options,
+	}
+}
+
+func (a arranger) Arrange(models []data.Model) (data.Model, error) {
+	if a.options.Arrange.SplitShells {
+		models = a.splitShells(models)
+	}
+
+	if len(models) == 1 {
+		return models[0], nil
+	}
+
+	brim := a.options.Printer.ExtrusionWidth * data.Micrometer(a.options.Print.BrimSkirt.BrimCount)
+	spacing := a.options.Arrange.Distance.ToMicrometer() + 2*brim
+
+	c := clip.NewClipper()
+	footprints := make([]data.Path, len(models))
+	for i, m := range models {
+		// Instances of a model are passed one after another, so the footprint can be reused.
+		if i > 0 && sameModel(m, models[i-1]) {
+			footprints[i] = footprints[i-1]
+			continue
+		}
+		footprints[i] = Footprint(c, m)
+	}
+
+	bedWidth, bedDepth := a.bedSize()
+	width, depth := estimateBedSize(footprints, spacing, bedWidth, bedDepth)
+	positions, err := Place(footprints, width, depth, spacing)
+
+	// The estimated size is too small if the footprints leave gaps, so it grows until all of them fit.
+	// A footprint which is bigger than the configured size can't fit at all.
+	var notFit *DoesNotFitError
+	for errors.As(err, &notFit) && (bedWidth == 0 || bedDepth == 0) && notFit.Width <= width && notFit.Depth <= depth {
+		if bedWidth == 0 {
+			width += width/10 + gridStep
+		}
+		if bedDepth == 0 {
+			depth += depth/10 + gridStep
+		}
+		positions, err = Place(footprints, width, depth, spacing)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.options.GoSlice.Logger.Printf("Arranged %v models\n", len(models))
+	return Merge(models, positions), nil
+}
+
+// splitShells replaces each model by its shells.
+// Shells which are inside the bounding box of another shell, like the walls of a cavity, stay with it.
+func (a arranger) splitShells(models []data.Model) []data.Model {
+	var result []data.Model
+	var lastModel data.Model
+	var lastShells []data.Model
+	for _, m := range models {
+		// Instances of a model are passed one after another, so the shells can be reused.
+		if lastModel != nil && sameModel(m, lastModel) {
+			result = append(result, lastShells...)
+			continue
+		}
+
+		lastModel = m
+		lastShells = groupShells(shells(m, a.options.Slicing.MeldDistance))
+		result = append(result, lastShells...)
+		a.options.GoSlice.Logger.Printf("Split model into %v shells\n", len(lastShells))
+	}
+
+	return result
+}
+
+// shells returns each group of faces which are connected through their points as separate model.
+// The points are compared in a grid with the size of the meldDistance.
+func shells(m data.Model, meldDistance data.Micrometer) []data.Model {
+	if meldDistance <= 0 {
+		meldDistance = 1
+	}
+
+	parents := make([]int, m.FaceCount())
+	find := func(i int) int {
+		for parents[i] != i {
+			parents[i] = parents[parents[i]]
+			i = parents[i]
+		}
+		return i
+	}
+
+	// Connect each face to the first face which uses the same point.
+	owners := make(map[[3]data.Micrometer]int)
+	for i := range parents {
+		parents[i] = i
+		for _, p := range m.Face(i).Points() {
+			key := [3]data.Micrometer{
+				(p.X() + meldDistance/2) / meldDistance,
+				(p.Y() + meldDistance/2) / meldDistance,
+				(p.Z() + meldDistance/2) / meldDistance,
+			}
+			owner, ok := owners[key]
+			if !ok {
+				owners[key] = i
+				continue
+			}
+			parents[find(i)] = find(owner)
+		}
+	}
+
+	groups := make(map[int]int)
+	var faces [][]data.Face
+	for i := range parents {
+		root := find(i)
+		group, ok := groups[root]
+		if !ok {
+			group = len(faces)
+			groups[root] = group
+			faces = append(faces, nil)
+		}
+		faces[group] = append(faces[group], m.Face(i))
+	}
+
+	result := make([]data.Model, len(faces))
+	for i := range faces {
+		result[i] = data.NewBasicModel(faces[i])
+	}
+
+	return result
+}
+
+// groupShells merges each shell into the biggest shell whose bounding box contains it.
+func groupShells(shells []data.Model) []data.Model {
+	// volume of the bounding box used to find the biggest shell
+	volume := func(m data.Model) float64 {
+		size := m.Max().Sub(m.Min())
+		return float64(size.X()) * float64(size.Y()) * float64(size.Z())
+	}
+
+	contains := func(outer, inner data.Model) bool {
+		oMin, oMax, iMin, iMax := outer.Min(), outer.Max(), inner.Min(), inner.Max()
+		return oMin.X() <= iMin.X() && oMin.Y() <= iMin.Y() && oMin.Z() <= iMin.Z() &&
+			oMax.X() >= iMax.X() && oMax.Y() >= iMax.Y() && oMax.Z() >= iMax.Z()
+	}
+
+	// Find the shell each shell belongs to.
+	parents := make([]int, len(shells))
+	for i, inner := range shells {
+		parents[i] = i
+		for j, outer := range shells {
+			if i == j || !contains(outer, inner) || volume(outer) <= volume(inner) {
+				continue
+			}
+			if parents[i] == i || volume(outer) > volume(shells[parents[i]]) {
+				parents[i] = j
+			}
+		}
+	}
+
+	faces := make([][]data.Face, len(shells))
+	for i, shell := range shells {
+		for j := 0; j < shell.FaceCount(); j++ {
+			faces[parents[i]] = append(faces[parents[i]], shell.Face(j))
+		}
+	}
+
+	var grouped []data.Model
+	for i := range shells {
+		if parents[i] == i {
+			grouped = append(grouped, data.NewBasicModel(faces[i]))
+		}
+	}
+
+	return grouped
+}
+
+// bedSize returns the rectangle to place the models in.
+// For a circular bed this is the biggest square inside of it.
+// A size of 0 means that the bed is not configured in this direction.
+func (a arranger) bedSize() (width, depth data.Micrometer) {
+	printer := a.options.Printer
+	width = printer.BedWidth.ToMicrometer()
+	depth = printer.BedDepth.ToMicrometer()
+	if printer.CircularBed {
+		width = data.Micrometer(float64(width) / math.Sqrt2)
+		depth = width
+	}
+
+	return width, depth
+}
+
+// estimateBedSize replaces the sizes which are 0 by a size which is just big enough for the area of all footprints
+// including the spacing. If both are 0, the footprints are placed on a square.
+// The size is never smaller than the biggest footprint.
+func estimateBedSize(footprints []data.Path, spacing, width, depth data.Micrometer) (data.Micrometer, data.Micrometer) {
+	var area float64
+	var maxWidth, maxDepth data.Micrometer
+	for _, f := range footprints {
+		min, max := f.Bounds()
+		area += float64(max.X()-min.X()+spacing) * float64(max.Y()-min.Y()+spacing)
+		if max.X()-min.X() > maxWidth {
+			maxWidth = max.X() - min.X()
+		}
+		if max.Y()-min.Y() > maxDepth {
+			maxDepth = max.Y() - min.Y()
+		}
+	}
+
+	estimatedWidth, estimatedDepth := width, depth
+	switch {
+	case width == 0 && depth == 0:
+		estimatedWidth = data.Micrometer(math.Ceil(math.Sqrt(area)))
+		estimatedDepth = estimatedWidth
+	case width == 0:
+		estimatedWidth = data.Micrometer(math.Ceil(area / float64(depth)))
+	case depth == 0:
+		estimatedDepth = data.Micrometer(math.Ceil(area / float64(width)))
+	}
+
+	if width == 0 && estimatedWidth < maxWidth {
+		estimatedWidth = maxWidth
+	}
+	if depth == 0 && estimatedDepth < maxDepth {
+		estimatedDepth = maxDepth
+	}
+
+	return estimatedWidth, estimatedDepth
+}
+
+// sameModel checks if both models are the same instance.
+// Models which can't be compared are never the same.
+func sameModel(a, b data.Model) bool {
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.TypeOf(a).Comparable() && a == b
+}
+
+// Footprint returns the convex hull of all points of the model projected onto the bed.
+// If no hull can be calculated, the bounding box is used.
+func Footprint(c clip.Clipper, m data.Model) data.Path {
+	points := make(data.Path, 0, m.FaceCount()*3)
+	for i := 0; i < m.FaceCount(); i++ {
+		for _, p := range m.Face(i).Points() {
+			points = append(points, p.PointXY())
+		}
+	}
+
+	hull, ok := c.Hull([]data.LayerPart{data.NewBasicLayerPart(points, nil)})
+	if ok && len(hull) > 0 {
+		return hull
+	}
+
+	min, max := m.Min(), m.Max()
+	return data.Path{
+		data.NewMicroPoint(min.X(), min.Y()),
+		data.NewMicroPoint(max.X(), min.Y()),
+		data.NewMicroPoint(max.X(), max.Y()),
+		data.NewMicroPoint(min.X(), max.Y()),
+	}
+}
+
+// point is a point in micrometer used for the placement calculations.
+type point struct {
+	x, y float64
+}
+
+func (p point) sub(o point) point {
+	return point{p.x - o.x, p.y - o.y}
+}
+
+// cross returns the z component of the cross product (o -> a) x (o -> b).
+func cross(o, a, b point) float64 {
+	return (a.x-o.x)*(b.y-o.y) - (a.y-o.y)*(b.x-o.x)
+}
+
+// footprint is a footprint which is moved so that its min corner is at 0, 0.
+type footprint struct {
+	points []point
+	width  float64
+	depth  float64
+}
+
+func newFootprint(path data.Path) *footprint {
+	min, max := path.Bounds()
+
+	f := &footprint{
+		points: make([]point, len(path)),
+		width:  float64(max.X() - min.X()),
+		depth:  float64(max.Y() - min.Y()),
+	}
+	for i, p := range path {
+		f.points[i] = point{float64(p.X() - min.X()), float64(p.Y() - min.Y())}
+	}
+
+	return f
+}
+
+// placedFootprint is a footprint at its final position.
+type placedFootprint struct {
+	*footprint
+	position point
+}
+
+// obstacle contains all positions at which a footprint would collide with an already placed footprint.
+// This is the so called no fit polygon. For convex footprints it is the convex hull of the
+// differences between all points of the placed footprint and all points of the new footprint.
+type obstacle struct {
+	hull     []point
+	min, max point
+}
+
+func newObstacle(placed placedFootprint, f *footprint) obstacle {
+	differences := make([]point, 0, len(placed.points)*len(f.points))
+	for _, p := range placed.points {
+		for _, q := range f.points {
+			differences = append(differences, point{p.x + placed.position.x - q.x, p.y + placed.position.y - q.y})
+		}
+	}
+
+	o := obstacle{
+		hull: convexHull(differences),
+		min:  point{math.Inf(1), math.Inf(1)},
+		max:  point{math.Inf(-1), math.Inf(-1)},
+	}
+	for _, p := range o.hull {
+		o.min = point{math.Min(o.min.x, p.x), math.Min(o.min.y, p.y)}
+		o.max = point{math.Max(o.max.x, p.x), math.Max(o.max.y, p.y)}
+	}
+
+	return o
+}
+
+// blocks checks if the position is nearer to the obstacle than the spacing.
+// The distance of the position to the obstacle is exactly the distance between both footprints.
+func (o obstacle) blocks(position point, spacing float64) bool {
+	// Fast check using the bounding box.
+	if position.x <= o.min.x-spacing || position.x >= o.max.x+spacing ||
+		position.y <= o.min.y-spacing || position.y >= o.max.y+spacing {
+		return false
+	}
+
+	// The hull is counterclockwise, so the position is inside if it is left of all edges.
+	if len(o.hull) >= 3 {
+		inside := true
+		for i := range o.hull {
+			if cross(o.hull[i], o.hull[(i+1)%len(o.hull)], position) < 0 {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			return true
+		}
+	}
+
+	for i := range o.hull {
+		if distanceToSegment(position, o.hull[i], o.hull[(i+1)%len(o.hull)]) < spacing {
+			return true
+		}
+	}
+
+	return false
+}
+
+// distanceToSegment returns the distance between the point p and the line segment from a to b.
+func distanceToSegment(p, a, b point) float64 {
+	ab := b.sub(a)
+	ap := p.sub(a)
+
+	length2 := ab.x*ab.x + ab.y*ab.y
+	t := 0.0
+	if length2 > 0 {
+		t = math.Max(0, math.Min(1, (ap.x*ab.x+ap.y*ab.y)/length2))
+	}
+
+	return math.Hypot(ap.x-t*ab.x, ap.y-t*ab.y)
+}
+
+// convexHull returns the counterclockwise convex hull of the points using the monotone chain algorithm.
+func convexHull(points []point) []point {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].x != points[j].x {
+			return points[i].x < points[j].x
+		}
+		return points[i].y < points[j].y
+	})
+
+	if len(points) < 3 {
+		return points
+	}
+
+	hull := make([]point, 0, 2*len(points))
+
+	// lower hull
+	for _, p := range points {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+
+	// upper hull
+	lower := len(hull) + 1
+	for i := len(points) - 2; i >= 0; i-- {
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], points[i]) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, points[i])
+	}
+
+	// The last point is the same as the first one.
+	return hull[:len(hull)-1]
+}
+
+// Place calculates the position of the min corner of each footprint on a bed with the given size (z is always 0).
+// The footprints have to be convex and keep at least the spacing to each other.
+// If a footprint does not fit onto the bed, a *DoesNotFitError is returned.
+func Place(footprints []data.Path, bedWidth, bedDepth, spacing data.Micrometer) ([]data.MicroVec3, error) {
+	prepared := make([]*footprint, len(footprints))
+	order := make([]int, len(footprints))
+	for i, path := range footprints {
+		prepared[i] = newFootprint(path)
+		order[i] = i
+	}
+
+	// Place the biggest footprints first, as the small ones fit more easily into the remaining gaps.
+	sort.SliceStable(order, func(i, j int) bool {
+		return prepared[order[i]].width*prepared[order[i]].depth > prepared[order[j]].width*prepared[order[j]].depth
+	})
+
+	positions := make([]data.MicroVec3, len(footprints))
+	placed := make([]placedFootprint, 0, len(footprints))
+	for _, i := range order {
+		f := prepared[i]
+
+		position, ok := findPosition(f, placed, float64(bedWidth), float64(bedDepth), float64(spacing))
+		if !ok {
+			return nil, &DoesNotFitError{
+				Index:    i,
+				Width:    data.Micrometer(f.width),
+				Depth:    data.Micrometer(f.depth),
+				BedWidth: bedWidth,
+				BedDepth: bedDepth,
+				Placed:   len(placed),
+			}
+		}
+
+		placed = append(placed, placedFootprint{footprint: f, position: position})
+		positions[i] = data.NewMicroVec3(data.Micrometer(position.x), data.Micrometer(position.y), 0)
+	}
+
+	return positions, nil
+}
+
+// findPosition returns the first free position for the footprint, searching row by row.
+func findPosition(f *footprint, placed []placedFootprint, bedWidth, bedDepth, spacing float64) (point, bool) {
+	if f.width > bedWidth || f.depth > bedDepth {
+		return point{}, false
+	}
+
+	obstacles := make([]obstacle, len(placed))
+	for i, other := range placed {
+		obstacles[i] = newObstacle(other, f)
+	}
+
+	// steps returns all positions from 0 to max including max itself.
+	steps := func(max float64) []float64 {
+		var result []float64
+		for v := 0.0; v < max; v += float64(gridStep) {
+			result = append(result, v)
+		}
+		return append(result, max)
+	}
+
+	xSteps := steps(bedWidth - f.width)
+	for _, y := range steps(bedDepth - f.depth) {
+	PositionLoop:
+		for _, x := range xSteps {
+			position := point{x, y}
+			for _, o := range obstacles {
+				if o.blocks(position, spacing) {
+					continue PositionLoop
+				}
+			}
+			return position, true
+		}
+	}
+
+	return point{}, false
+}
+
+// Merge moves the min corner of each model to its position and merges all of them into one model.
+func Merge(models []data.Model, positions []data.MicroVec3) data.Model {
+	faceCount := 0
+	for _, m := range models {
+		faceCount += m.FaceCount()
+	}
+
+	faces := make([]data.Face, 0, faceCount)
+	for i, m := range models {
+		offset := m.Min().Sub(positions[i])
+		for j := 0; j < m.FaceCount(); j++ {
+			points := m.Face(j).Points()
+			for k := range points {
+				points[k] = points[k].Sub(offset)
+			}
+			faces = append(faces, data.NewBasicFace(points))
+		}
+	}
+
+	return data.NewBasicModel(faces)
+}
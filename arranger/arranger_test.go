@@ -0,0 +1,213 @@
+package arranger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aligator/goslice/arranger"
+	"github.com/aligator/goslice/clip"
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/util/test"
+)
+
+// boxModel is a single face spanning a box of the given size which starts at the given min corner.
+func boxModel(min data.MicroVec3, x, y, z data.Micrometer) data.Model {
+	return data.NewBasicModel([]data.Face{
+		data.NewBasicFace([3]data.MicroVec3{
+			min,
+			min.Add(data.NewMicroVec3(x, 0, 0)),
+			min.Add(data.NewMicroVec3(0, y, z)),
+		}),
+	})
+}
+
+// square returns a square footprint with the given size in mm.
+func square(size data.Millimeter) data.Path {
+	s := size.ToMicrometer()
+	return data.Path{
+		data.NewMicroPoint(-s/2, -s/2),
+		data.NewMicroPoint(s/2, -s/2),
+		data.NewMicroPoint(s/2, s/2),
+		data.NewMicroPoint(-s/2, s/2),
+	}
+}
+
+func TestPlace(t *testing.T) {
+	var tests = map[string]struct {
+		footprints []data.Path
+		bedWidth   data.Millimeter
+		bedDepth   data.Millimeter
+		expected   []data.MicroVec3
+	}{
+		"squares": {
+			footprints: []data.Path{square(10), square(10), square(10), square(10)},
+			bedWidth:   30,
+			bedDepth:   30,
+			expected: []data.MicroVec3{
+				data.NewMicroVec3(0, 0, 0),
+				data.NewMicroVec3(12000, 0, 0),
+				data.NewMicroVec3(0, 12000, 0),
+				data.NewMicroVec3(12000, 12000, 0),
+			},
+		},
+		"biggest first": {
+			footprints: []data.Path{square(5), square(20)},
+			bedWidth:   30,
+			bedDepth:   30,
+			expected: []data.MicroVec3{
+				data.NewMicroVec3(22000, 0, 0),
+				data.NewMicroVec3(0, 0, 0),
+			},
+		},
+		"triangles fit into each other": {
+			footprints: []data.Path{
+				{data.NewMicroPoint(0, 0), data.NewMicroPoint(20000, 0), data.NewMicroPoint(0, 20000)},
+				{data.NewMicroPoint(20000, 0), data.NewMicroPoint(20000, 20000), data.NewMicroPoint(0, 20000)},
+			},
+			bedWidth: 30,
+			bedDepth: 22,
+			expected: []data.MicroVec3{
+				data.NewMicroVec3(0, 0, 0),
+				data.NewMicroVec3(3000, 0, 0),
+			},
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log(name)
+
+		positions, err := arranger.Place(testCase.footprints, testCase.bedWidth.ToMicrometer(), testCase.bedDepth.ToMicrometer(), 2000)
+		test.Ok(t, err)
+		test.Equals(t, len(testCase.expected), len(positions))
+		for i := range testCase.expected {
+			test.Equals(t, testCase.expected[i].String(), positions[i].String())
+		}
+	}
+}
+
+func TestPlaceDoesNotFit(t *testing.T) {
+	var tests = map[string]struct {
+		footprints []data.Path
+		placed     int
+	}{
+		"too many": {
+			footprints: []data.Path{square(20), square(20), square(20)},
+			placed:     1,
+		},
+		"too big": {
+			footprints: []data.Path{square(10), square(40)},
+			placed:     0,
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log(name)
+
+		_, err := arranger.Place(testCase.footprints, 30000, 30000, 2000)
+		var notFit *arranger.DoesNotFitError
+		test.Assert(t, errors.As(err, &notFit), "expected a DoesNotFitError but got %v", err)
+		test.Equals(t, testCase.placed, notFit.Placed)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	small := boxModel(data.NewMicroVec3(-5000, -5000, 3000), 10000, 10000, 10000)
+	big := boxModel(data.NewMicroVec3(0, 0, -1000), 20000, 30000, 5000)
+
+	merged := arranger.Merge([]data.Model{small, big}, []data.MicroVec3{
+		data.NewMicroVec3(0, 0, 0),
+		data.NewMicroVec3(15000, 0, 0),
+	})
+
+	test.Equals(t, 2, merged.FaceCount())
+	test.Equals(t, data.NewMicroVec3(0, 0, 0).String(), merged.Min().String())
+	test.Equals(t, data.NewMicroVec3(35000, 30000, 10000).String(), merged.Max().String())
+	test.Equals(t, data.NewMicroVec3(15000, 0, 0).String(), merged.Face(1).Points()[0].String())
+}
+
+func TestArrangeSingleModel(t *testing.T) {
+	options := data.DefaultOptions()
+	model := boxModel(data.NewMicroVec3(-5000, -5000, 3000), 10000, 10000, 10000)
+
+	result, err := arranger.NewArranger(&options).Arrange([]data.Model{model})
+	test.Ok(t, err)
+	test.Assert(t, result == model, "a single model should not be changed")
+}
+
+func TestFootprint(t *testing.T) {
+	model := boxModel(data.NewMicroVec3(-5000, -5000, 3000), 10000, 10000, 10000)
+	footprint := arranger.Footprint(clip.NewClipper(), model)
+
+	min, max := footprint.Bounds()
+	test.Equals(t, [2]data.Micrometer{-5000, -5000}, [2]data.Micrometer{min.X(), min.Y()})
+	test.Equals(t, [2]data.Micrometer{5000, 5000}, [2]data.Micrometer{max.X(), max.Y()})
+	test.Equals(t, 3, len(footprint))
+}
+
+func TestArrangeCircularBed(t *testing.T) {
+	model := boxModel(data.NewMicroVec3(0, 0, 0), 10000, 10000, 10000)
+
+	options := data.DefaultOptions()
+	options.Printer.CircularBed = true
+	options.Arrange.Distance = 5
+
+	// The biggest square inside of the bed is about 28 x 28 mm, so two models fit next to each other.
+	options.Printer.BedWidth = 40
+	_, err := arranger.NewArranger(&options).Arrange([]data.Model{model, model})
+	test.Ok(t, err)
+
+	// The biggest square inside of the bed is about 17 x 17 mm, which is too small for two models.
+	options.Printer.BedWidth = 24
+	_, err = arranger.NewArranger(&options).Arrange([]data.Model{model, model})
+	var notFit *arranger.DoesNotFitError
+	test.Assert(t, errors.As(err, &notFit), "expected a DoesNotFitError but got %v", err)
+}
+
+func TestArrangeWithoutBed(t *testing.T) {
+	model := data.NewBasicModel(test.CubeFaces(data.NewMicroVec3(0, 0, 0), 20000))
+	models := make([]data.Model, 9)
+	for i := range models {
+		models[i] = model
+	}
+
+	// Without a bed size the models are placed on a square.
+	options := data.DefaultOptions()
+	options.Arrange.Distance = 5
+	options.Print.BrimSkirt.BrimCount = 0
+	result, err := arranger.NewArranger(&options).Arrange(models)
+	test.Ok(t, err)
+	test.Equals(t, data.NewMicroVec3(70000, 70000, 20000).String(), result.Max().Sub(result.Min()).String())
+
+	// With only the depth configured, the models are placed in as few rows as possible.
+	options.Printer.BedDepth = 50
+	result, err = arranger.NewArranger(&options).Arrange(models)
+	test.Ok(t, err)
+	test.Equals(t, data.NewMicroVec3(120000, 45000, 20000).String(), result.Max().Sub(result.Min()).String())
+
+	// A model which is bigger than the configured depth does not fit.
+	options.Printer.BedDepth = 15
+	_, err = arranger.NewArranger(&options).Arrange(models)
+	var notFit *arranger.DoesNotFitError
+	test.Assert(t, errors.As(err, &notFit), "expected a DoesNotFitError but got %v", err)
+}
+
+func TestArrangeSplitShells(t *testing.T) {
+	// Two cubes with 1 mm between them. The first one contains a small cube as cavity.
+	faces := test.CubeFaces(data.NewMicroVec3(0, 0, 0), 10000)
+	faces = append(faces, test.CubeFaces(data.NewMicroVec3(11000, 0, 0), 10000)...)
+	faces = append(faces, test.CubeFaces(data.NewMicroVec3(3000, 3000, 3000), 2000)...)
+	model := data.NewBasicModel(faces)
+
+	options := data.DefaultOptions()
+	options.Printer.BedWidth = 50
+	options.Printer.BedDepth = 50
+	options.Arrange.Distance = 5
+	options.Arrange.SplitShells = true
+
+	result, err := arranger.NewArranger(&options).Arrange([]data.Model{model})
+	test.Ok(t, err)
+
+	// The cubes are placed with 5 mm between them and the cavity stays in the first cube.
+	test.Equals(t, 36, result.FaceCount())
+	test.Equals(t, data.NewMicroVec3(25000, 10000, 10000).String(), result.Max().Sub(result.Min()).String())
+}
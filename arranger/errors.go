@@ -0,0 +1,35 @@
+// This file provides the errors returned by the arranger.
+
+package arranger
+
+import (
+	"fmt"
+
+	"github.com/aligator/goslice/data"
+)
+
+// DoesNotFitError is returned if a model can't be placed onto the bed.
+type DoesNotFitError struct {
+	// Index is the index of the model which does not fit.
+	Index int
+
+	// Width and Depth are the size of the footprint of the model.
+	Width data.Micrometer
+	Depth data.Micrometer
+
+	// BedWidth and BedDepth are the size of the bed.
+	BedWidth data.Micrometer
+	BedDepth data.Micrometer
+
+	// Placed is the amount of models which were placed onto the bed before.
+	Placed int
+}
+
+func (e *DoesNotFitError) Error() string {
+	if e.Width > e.BedWidth || e.Depth > e.BedDepth {
+		return fmt.Sprintf("model %d with a footprint of %v x %v mm is bigger than the bed with %v x %v mm",
+			e.Index, e.Width.ToMillimeter(), e.Depth.ToMillimeter(), e.BedWidth.ToMillimeter(), e.BedDepth.ToMillimeter())
+	}
+	return fmt.Sprintf("model %d with a footprint of %v x %v mm does not fit onto the bed with %v x %v mm beside the %d models already placed",
+		e.Index, e.Width.ToMillimeter(), e.Depth.ToMillimeter(), e.BedWidth.ToMillimeter(), e.BedDepth.ToMillimeter(), e.Placed)
+}
@@ -12,8 +12,9 @@ import (
 // Pattern is an interface for all infill types which can be used to fill layer parts.
 type Pattern interface {
 	// Fill fills the given part.
+	// The z is the height of the layer (see data.PartitionedLayer.Z) for patterns which change with the height.
 	// It returns the final infill pattern.
-	Fill(layerNr int, part data.LayerPart) (data.Paths, error)
+	Fill(layerNr int, z data.Micrometer, part data.LayerPart) (data.Paths, error)
 }
 
 // OffsetResult is built the following way: [partNr][insetNr][insetPartsNr]data.LayerPart
@@ -208,7 +209,7 @@ func (c clipperClipper) GenerateLayerParts(l data.Layer) (data.PartitionedLayer,
 	}
 
 	if len(polyList) == 0 {
-		return data.NewPartitionedLayer([]data.LayerPart{}), true
+		return data.NewPartitionedLayer([]data.LayerPart{}, l.Z(), l.Thickness()), true
 	}
 
 	cl := clipper.NewClipper(clipper.IoNone)
@@ -218,7 +219,7 @@ func (c clipperClipper) GenerateLayerParts(l data.Layer) (data.PartitionedLayer,
 		return nil, false
 	}
 
-	return data.NewPartitionedLayer(polyTreeToLayerParts(resultPolys)), true
+	return data.NewPartitionedLayer(polyTreeToLayerParts(resultPolys), l.Z(), l.Thickness()), true
 }
 
 // polyTreeToLayerParts creates layer parts out of a poly tree (which is the result of clipper's Execute2).
@@ -0,0 +1,64 @@
+// This file provides a concentric infill pattern.
+
+package clip
+
+import (
+	"github.com/aligator/goslice/data"
+)
+
+// concentric fills the parts with loops which follow the outline and the holes of the part.
+type concentric struct {
+	lineWidth    data.Micrometer
+	lineDistance data.Micrometer
+}
+
+// NewConcentricPattern provides a pattern which insets the part again and again until it is filled.
+// The first loop is half the lineWidth away from the border of the part, all others have the lineDistance to the previous one.
+// For solid areas the lineDistance is the lineWidth.
+func NewConcentricPattern(lineWidth data.Micrometer, lineDistance data.Micrometer) Pattern {
+	return concentric{
+		lineWidth:    lineWidth,
+		lineDistance: lineDistance,
+	}
+}
+
+// Fill implements the Pattern interface by insetting the part until nothing is left.
+// Each loop starts next to the end of the previous one.
+func (p concentric) Fill(layerNr int, z data.Micrometer, part data.LayerPart) (data.Paths, error) {
+	if p.lineDistance <= 0 {
+		return nil, nil
+	}
+
+	c := NewClipper()
+
+	var result data.Paths
+	var last data.MicroPoint
+
+	// Each inset is calculated from the previous one as this is much faster than insetting the whole part again.
+	insets := c.Inset(part, p.lineDistance, 1, -p.lineWidth/2)[0]
+	for len(insets) > 0 {
+		var next []data.LayerPart
+		for _, inset := range insets {
+			for _, loop := range append(data.Paths{inset.Outline()}, inset.Holes()...) {
+				if len(loop) < 3 {
+					continue
+				}
+
+				start := loop[0]
+				if last != nil {
+					start = last
+				}
+				loop = loop.StartNearest(start)
+
+				// Close the loop as the paths of a pattern are open.
+				result = append(result, append(loop, loop[0]))
+				last = loop[0]
+			}
+
+			next = append(next, c.Inset(inset, p.lineDistance, 1, -p.lineDistance)[0]...)
+		}
+		insets = next
+	}
+
+	return result, nil
+}
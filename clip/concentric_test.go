@@ -0,0 +1,45 @@
+package clip_test
+
+import (
+	"testing"
+
+	"github.com/aligator/goslice/clip"
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/util/test"
+)
+
+func TestConcentricPattern(t *testing.T) {
+	size := data.Micrometer(20000)
+	pattern := clip.NewConcentricPattern(400, 2000)
+
+	paths, err := pattern.Fill(0, 200, square(size))
+	test.Ok(t, err)
+
+	// The loops have a distance of 200, 2200, 4200, 6200 and 8200 to the border.
+	test.Equals(t, 5, len(paths))
+	for i, path := range paths {
+		first, last := path[0], path[len(path)-1]
+		test.Assert(t, first.X() == last.X() && first.Y() == last.Y(), "the loop %v should be closed", i)
+
+		min, max := path.Bounds()
+		distance := data.Micrometer(200 + i*2000)
+		test.Equals(t, distance, min.X())
+		test.Equals(t, distance, min.Y())
+		test.Equals(t, size-distance, max.X())
+		test.Equals(t, size-distance, max.Y())
+	}
+
+	// A hole gets its own loops.
+	withHole := data.NewBasicLayerPart(square(size).Outline(), data.Paths{{
+		data.NewMicroPoint(8000, 8000),
+		data.NewMicroPoint(8000, 12000),
+		data.NewMicroPoint(12000, 12000),
+		data.NewMicroPoint(12000, 8000),
+	}})
+	paths, err = pattern.Fill(0, 200, withHole)
+	test.Ok(t, err)
+
+	// There are two loops around the outline and two around the hole.
+	// Between them only the corners are wide enough for another loop.
+	test.Equals(t, 8, len(paths))
+}
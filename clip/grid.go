@@ -0,0 +1,130 @@
+// This file provides infill patterns which consist of straight lines in several directions on each layer.
+
+package clip
+
+import (
+	"math"
+
+	"github.com/aligator/goslice/data"
+)
+
+// grid fills the parts with parallel lines in several directions on each layer.
+// All lines are aligned to the origin, so they are the same on all parts and layers
+// unless they are moved by the shift.
+type grid struct {
+	lineDistance data.Micrometer
+	degrees      []float64
+	min, max     data.MicroPoint
+
+	// shift returns the distance the lines of each direction are moved at the given height.
+	// It may be nil if the lines are not moved.
+	shift func(z data.Micrometer) data.Micrometer
+}
+
+// NewGridPattern provides a pattern of lines in two directions crossing each other at 90° on each layer.
+// The lineDistance is the distance of the lines of a linear pattern with the same density,
+// so each direction uses the double distance.
+// Min and max define the dimension of the model.
+func NewGridPattern(lineDistance data.Micrometer, min data.MicroPoint, max data.MicroPoint, degree int) Pattern {
+	return grid{
+		lineDistance: lineDistance * 2,
+		degrees:      []float64{float64(degree), float64(degree) + 90},
+		min:          min,
+		max:          max,
+	}
+}
+
+// NewTrianglesPattern provides a pattern of lines in three directions with 60° between them on each layer.
+// The lines of all directions meet at the same points, so they form triangles.
+// The lineDistance is the distance of the lines of a linear pattern with the same density,
+// so each direction uses the triple distance.
+// Min and max define the dimension of the model.
+func NewTrianglesPattern(lineDistance data.Micrometer, min data.MicroPoint, max data.MicroPoint, degree int) Pattern {
+	return grid{
+		lineDistance: lineDistance * 3,
+		degrees:      []float64{float64(degree), float64(degree) + 60, float64(degree) + 120},
+		min:          min,
+		max:          max,
+	}
+}
+
+// NewCubicPattern provides a pattern of cubes standing on one of their corners.
+// Each layer cuts the cubes which results in lines in three directions with 120° between them.
+// These lines move with the height of the layer, so they form the walls of the cubes.
+// The lineDistance is the distance of the lines of a linear pattern with the same density,
+// so each direction uses the triple distance.
+// Min and max define the dimension of the model.
+func NewCubicPattern(lineDistance data.Micrometer, min data.MicroPoint, max data.MicroPoint, degree int) Pattern {
+	return grid{
+		lineDistance: lineDistance * 3,
+		degrees:      []float64{float64(degree), float64(degree) + 120, float64(degree) + 240},
+		min:          min,
+		max:          max,
+		shift: func(z data.Micrometer) data.Micrometer {
+			// The walls of a cube standing on its corner move by 1/sqrt(2) of the height.
+			return data.Micrometer(float64(z) / math.Sqrt2)
+		},
+	}
+}
+
+// Fill implements the Pattern interface by generating the lines of all directions, clipping them by the part
+// and connecting them.
+func (p grid) Fill(layerNr int, z data.Micrometer, part data.LayerPart) (data.Paths, error) {
+	if p.lineDistance <= 0 {
+		return nil, nil
+	}
+
+	var shift data.Micrometer
+	if p.shift != nil {
+		shift = p.shift(z)
+	}
+
+	var lines data.Paths
+	for _, degree := range p.degrees {
+		lines = append(lines, p.lines(degree, shift)...)
+	}
+
+	clipped, err := clipLines(part, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	return connectLines(part, clipped, p.lineDistance)
+}
+
+// lines generates parallel lines with the given rotation over the whole model.
+// The lines are moved by the shift orthogonal to their direction.
+func (p grid) lines(degree float64, shift data.Micrometer) data.Paths {
+	radians := degree * math.Pi / 180
+	dirX, dirY := math.Cos(radians), math.Sin(radians)
+	normalX, normalY := -dirY, dirX
+
+	// Project the corners of the model onto the direction and the normal to get the needed line positions and lengths.
+	corners := [][2]float64{
+		{float64(p.min.X()), float64(p.min.Y())},
+		{float64(p.max.X()), float64(p.min.Y())},
+		{float64(p.max.X()), float64(p.max.Y())},
+		{float64(p.min.X()), float64(p.max.Y())},
+	}
+	minU, maxU := math.Inf(1), math.Inf(-1)
+	minT, maxT := math.Inf(1), math.Inf(-1)
+	for _, c := range corners {
+		u := c[0]*dirX + c[1]*dirY
+		t := c[0]*normalX + c[1]*normalY
+		minU, maxU = math.Min(minU, u), math.Max(maxU, u)
+		minT, maxT = math.Min(minT, t), math.Max(maxT, t)
+	}
+
+	distance := float64(p.lineDistance)
+	offset := math.Mod(float64(shift), distance)
+
+	var result data.Paths
+	for t := math.Ceil((minT-offset)/distance)*distance + offset; t <= maxT; t += distance {
+		result = append(result, data.Path{
+			data.NewMicroPoint(data.Micrometer(math.Round(t*normalX+minU*dirX)), data.Micrometer(math.Round(t*normalY+minU*dirY))),
+			data.NewMicroPoint(data.Micrometer(math.Round(t*normalX+maxU*dirX)), data.Micrometer(math.Round(t*normalY+maxU*dirY))),
+		})
+	}
+
+	return result
+}
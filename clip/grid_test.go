@@ -0,0 +1,125 @@
+package clip_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aligator/goslice/clip"
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/util/test"
+)
+
+// directions returns the rounded directions (0° to 179°) of all segments which are longer than minLength.
+func directions(paths data.Paths, minLength data.Micrometer) map[int]bool {
+	result := map[int]bool{}
+	for _, path := range paths {
+		for i := 1; i < len(path); i++ {
+			d := path[i].Sub(path[i-1])
+			if d.ShorterThanOrEqual(minLength) {
+				continue
+			}
+			degree := int(math.Round(math.Atan2(float64(d.Y()), float64(d.X()))*180/math.Pi+360)) % 180
+			result[degree] = true
+		}
+	}
+	return result
+}
+
+// equalPaths checks if both have the same points.
+func equalPaths(a, b data.Paths) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false
+		}
+		for j := range a[i] {
+			if a[i][j].X() != b[i][j].X() || a[i][j].Y() != b[i][j].Y() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestGridPatterns(t *testing.T) {
+	size := data.Micrometer(20000)
+	lineDistance := data.Micrometer(2000)
+	min, max := data.NewMicroPoint(0, 0), data.NewMicroPoint(size, size)
+
+	var tests = map[string]struct {
+		pattern      clip.Pattern
+		directions   map[int]bool
+		changesWithZ bool
+	}{
+		"grid": {
+			pattern:    clip.NewGridPattern(lineDistance, min, max, 0),
+			directions: map[int]bool{0: true, 90: true},
+		},
+		"triangles": {
+			pattern:    clip.NewTrianglesPattern(lineDistance, min, max, 0),
+			directions: map[int]bool{0: true, 60: true, 120: true},
+		},
+		"cubic": {
+			pattern:      clip.NewCubicPattern(lineDistance, min, max, 0),
+			directions:   map[int]bool{0: true, 60: true, 120: true},
+			changesWithZ: true,
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log("test case:", name)
+
+		layer0, err := testCase.pattern.Fill(0, 200, square(size))
+		test.Ok(t, err)
+		layer10, err := testCase.pattern.Fill(10, 2200, square(size))
+		test.Ok(t, err)
+
+		test.Assert(t, len(layer0) > 0, "the part should be filled")
+		for _, path := range layer0 {
+			for _, p := range path {
+				test.Assert(t, p.X() >= 0 && p.X() <= size && p.Y() >= 0 && p.Y() <= size, "the point %v should be inside of the part", p)
+			}
+		}
+
+		// The connections at the border are not longer than the distance of the lines of one direction,
+		// so only the long lines are checked.
+		test.Equals(t, testCase.directions, directions(layer0, 3*lineDistance))
+
+		// All patterns should use about the same amount of material as a linear pattern.
+		straight := float64(size) * float64(size) / float64(lineDistance)
+		test.Assert(t, length(layer0) > 0.9*straight && length(layer0) < 1.5*straight, "unexpected length %v of the infill", length(layer0))
+
+		test.Equals(t, testCase.changesWithZ, !equalPaths(layer0, layer10))
+	}
+}
+
+func TestCubicPatternAdaptiveLayers(t *testing.T) {
+	size := data.Micrometer(20000)
+	lineDistance := data.Micrometer(2000)
+	pattern := clip.NewCubicPattern(lineDistance, data.NewMicroPoint(0, 0), data.NewMicroPoint(size, size), 0)
+
+	// Layers with a thickness of 0.1 mm and 0.3 mm.
+	for layerNr, z := range []data.Micrometer{100, 400, 500, 800, 900, 1200} {
+		paths, err := pattern.Fill(layerNr, z, square(size))
+		test.Ok(t, err)
+
+		// The lines in 0° move with 1/sqrt(2) of the height, so they form the walls of the cubes.
+		// Each direction uses the triple distance.
+		distance := float64(3 * lineDistance)
+		expected := math.Mod(float64(z)/math.Sqrt2, distance)
+		found := false
+		for _, path := range paths {
+			for i := 1; i < len(path); i++ {
+				if path[i].Y() != path[i-1].Y() || path[i].Sub(path[i-1]).ShorterThanOrEqual(3*lineDistance) {
+					continue
+				}
+				found = true
+				offset := math.Mod(float64(path[i].Y()), distance)
+				test.Assert(t, math.Abs(offset-expected) <= 1, "the line at %v should be moved to %v at the height %v", path[i].Y(), expected, z)
+			}
+		}
+		test.Assert(t, found, "the layer at the height %v should contain lines in 0°", z)
+	}
+}
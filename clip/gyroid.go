@@ -0,0 +1,211 @@
+// This file provides a gyroid infill pattern.
+
+package clip
+
+import (
+	"math"
+	"sort"
+
+	"github.com/aligator/goslice/data"
+)
+
+// gyroidResolution is the amount of grid cells per period which are used to trace the gyroid lines.
+const gyroidResolution = 16
+
+// gyroid provides an infill pattern based on the gyroid surface sin(x)*cos(y) + sin(y)*cos(z) + sin(z)*cos(x) = 0.
+// Each layer is a cut through this surface at the height of the layer.
+// The resulting wave lines change from layer to layer, so that they form a 3d structure
+// which is similarly strong in all directions.
+type gyroid struct {
+	lineDistance data.Micrometer
+}
+
+// NewGyroidPattern provides a gyroid infill pattern.
+// The lineDistance is the average distance between two neighbouring lines.
+func NewGyroidPattern(lineDistance data.Micrometer) Pattern {
+	return gyroid{
+		lineDistance: lineDistance,
+	}
+}
+
+// Fill implements the Pattern interface by tracing the gyroid lines of the layer, clipping them by the part
+// and connecting them.
+func (p gyroid) Fill(layerNr int, z data.Micrometer, part data.LayerPart) (data.Paths, error) {
+	if p.lineDistance <= 0 || len(part.Outline()) == 0 {
+		return nil, nil
+	}
+
+	// One period of the gyroid contains two lines in each direction.
+	period := float64(2 * p.lineDistance)
+	scale := 2 * math.Pi / period
+	lines := traceGyroid(part, period/gyroidResolution, scale, float64(z)*scale)
+
+	clipped, err := clipLines(part, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	return connectLines(part, clipped, 2*p.lineDistance)
+}
+
+// traceGyroid calculates the lines of the gyroid at the height z in the bounding box of the part
+// using marching squares with the given cell size.
+func traceGyroid(part data.LayerPart, cellSize, scale, z float64) data.Paths {
+	min, max := part.Outline().Bounds()
+
+	// Add one cell on each side so that the lines always leave the part.
+	minX := float64(min.X()) - cellSize
+	minY := float64(min.Y()) - cellSize
+	nx := int(math.Ceil((float64(max.X())-minX)/cellSize)) + 1
+	ny := int(math.Ceil((float64(max.Y())-minY)/cellSize)) + 1
+
+	sinZ, cosZ := math.Sin(z), math.Cos(z)
+	value := func(x, y float64) float64 {
+		x, y = x*scale, y*scale
+		return math.Sin(x)*math.Cos(y) + math.Sin(y)*cosZ + sinZ*math.Cos(x)
+	}
+
+	values := make([][]float64, nx+1)
+	for i := range values {
+		values[i] = make([]float64, ny+1)
+		for j := range values[i] {
+			values[i][j] = value(minX+float64(i)*cellSize, minY+float64(j)*cellSize)
+		}
+	}
+
+	return traceIsoLines(values, minX, minY, cellSize)
+}
+
+// traceIsoLines uses marching squares to calculate the lines where the values on the grid are 0.
+// values[i][j] is the value at minX + i*cellSize, minY + j*cellSize.
+// The segments of all cells are joined to paths, closed lines start and end at the same point.
+func traceIsoLines(values [][]float64, minX, minY, cellSize float64) data.Paths {
+	nx := len(values) - 1
+	if nx < 1 {
+		return nil
+	}
+	ny := len(values[0]) - 1
+
+	// Each edge of the grid is identified by an id:
+	// 2 * (j*(nx+1) + i) for the edge from (i, j) to (i+1, j) and
+	// 2 * (j*(nx+1) + i) + 1 for the edge from (i, j) to (i, j+1).
+	horizontal := func(i, j int) int { return 2 * (j*(nx+1) + i) }
+	vertical := func(i, j int) int { return 2*(j*(nx+1)+i) + 1 }
+	positive := func(i, j int) bool { return values[i][j] >= 0 }
+
+	// point calculates the position of the 0 on the edge by linear interpolation.
+	point := func(edge int) data.MicroPoint {
+		i := (edge / 2) % (nx + 1)
+		j := (edge / 2) / (nx + 1)
+		i2, j2 := i+1, j
+		if edge%2 == 1 {
+			i2, j2 = i, j+1
+		}
+
+		v1, v2 := values[i][j], values[i2][j2]
+		t := v1 / (v1 - v2)
+		x := minX + (float64(i)+t*float64(i2-i))*cellSize
+		y := minY + (float64(j)+t*float64(j2-j))*cellSize
+		return data.NewMicroPoint(data.Micrometer(math.Round(x)), data.Micrometer(math.Round(y)))
+	}
+
+	neighbours := map[int][]int{}
+	connect := func(a, b int) {
+		neighbours[a] = append(neighbours[a], b)
+		neighbours[b] = append(neighbours[b], a)
+	}
+
+	for i := 0; i < nx; i++ {
+		for j := 0; j < ny; j++ {
+			// a is the bottom left corner, then counter clockwise.
+			a, b, c, d := positive(i, j), positive(i+1, j), positive(i+1, j+1), positive(i, j+1)
+			bottom, right, top, left := horizontal(i, j), vertical(i+1, j), horizontal(i, j+1), vertical(i, j)
+
+			var crossed []int
+			if a != b {
+				crossed = append(crossed, bottom)
+			}
+			if b != c {
+				crossed = append(crossed, right)
+			}
+			if c != d {
+				crossed = append(crossed, top)
+			}
+			if d != a {
+				crossed = append(crossed, left)
+			}
+
+			switch len(crossed) {
+			case 2:
+				connect(crossed[0], crossed[1])
+			case 4:
+				// A saddle: the value in the center decides which corners are connected.
+				center := (values[i][j]+values[i+1][j]+values[i+1][j+1]+values[i][j+1])/4 >= 0
+				if center == a {
+					connect(bottom, right)
+					connect(top, left)
+				} else {
+					connect(left, bottom)
+					connect(right, top)
+				}
+			}
+		}
+	}
+
+	visited := map[int]bool{}
+	var result data.Paths
+
+	walk := func(start int) {
+		path := data.Path{point(start)}
+		visited[start] = true
+		current := start
+		for {
+			next := -1
+			for _, n := range neighbours[current] {
+				if !visited[n] {
+					next = n
+					break
+				}
+			}
+			if next == -1 {
+				// Close the path if it is a loop.
+				for _, n := range neighbours[current] {
+					if n == start && len(path) > 2 {
+						path = append(path, path[0])
+						break
+					}
+				}
+				break
+			}
+
+			visited[next] = true
+			path = append(path, point(next))
+			current = next
+		}
+
+		if len(path) > 1 {
+			result = append(result, path)
+		}
+	}
+
+	// Sort the edges so that the result does not depend on the map order.
+	edges := make([]int, 0, len(neighbours))
+	for edge := range neighbours {
+		edges = append(edges, edge)
+	}
+	sort.Ints(edges)
+
+	// First walk all open lines from one of their ends, then the remaining loops.
+	for _, edge := range edges {
+		if !visited[edge] && len(neighbours[edge]) == 1 {
+			walk(edge)
+		}
+	}
+	for _, edge := range edges {
+		if !visited[edge] {
+			walk(edge)
+		}
+	}
+
+	return result
+}
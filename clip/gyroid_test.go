@@ -0,0 +1,62 @@
+package clip_test
+
+import (
+	"testing"
+
+	"github.com/aligator/goslice/clip"
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/util/test"
+)
+
+// square returns a part with the given size in micrometer and its min corner at 0, 0.
+func square(size data.Micrometer) data.LayerPart {
+	return data.NewBasicLayerPart(data.Path{
+		data.NewMicroPoint(0, 0),
+		data.NewMicroPoint(size, 0),
+		data.NewMicroPoint(size, size),
+		data.NewMicroPoint(0, size),
+	}, nil)
+}
+
+// length returns the summed up length of all paths in micrometer.
+func length(paths data.Paths) float64 {
+	var result float64
+	for _, path := range paths {
+		for i := 1; i < len(path); i++ {
+			result += float64(path[i].Sub(path[i-1]).Size())
+		}
+	}
+	return result
+}
+
+func TestGyroidPattern(t *testing.T) {
+	size := data.Micrometer(20000)
+	lineDistance := data.Micrometer(2000)
+	pattern := clip.NewGyroidPattern(lineDistance)
+
+	layer0, err := pattern.Fill(0, 200, square(size))
+	test.Ok(t, err)
+	layer10, err := pattern.Fill(10, 2200, square(size))
+	test.Ok(t, err)
+
+	for _, paths := range []data.Paths{layer0, layer10} {
+		test.Assert(t, len(paths) > 0, "the part should be filled")
+
+		for _, path := range paths {
+			for _, p := range path {
+				test.Assert(t, p.X() >= 0 && p.X() <= size && p.Y() >= 0 && p.Y() <= size, "the point %v should be inside of the part", p)
+			}
+		}
+
+		// The lines are wavy and connected, so they are a bit longer than straight lines with the same distance.
+		straight := float64(size) * float64(size) / float64(lineDistance)
+		test.Assert(t, length(paths) > straight && length(paths) < 2*straight, "unexpected length %v of the infill", length(paths))
+	}
+
+	test.Assert(t, layer0[0][0].X() != layer10[0][0].X() || layer0[0][0].Y() != layer10[0][0].Y(), "the pattern should change with the height")
+
+	// Only the height matters, so layers with a different thickness below them still match.
+	adaptive, err := pattern.Fill(3, 2200, square(size))
+	test.Ok(t, err)
+	test.Assert(t, equalPaths(layer10, adaptive), "the pattern should only depend on the height of the layer")
+}
@@ -0,0 +1,110 @@
+// This file provides a honeycomb infill pattern.
+
+package clip
+
+import (
+	"math"
+
+	"github.com/aligator/goslice/data"
+)
+
+// honeycomb fills the parts with hexagonal cells.
+// The cells are built by zig-zag lines which run in columns. Each line consists of vertical parts on both sides
+// of its column connected by diagonals. The lines of neighbouring columns are offset by half a period,
+// so they share their vertical parts and form the hexagons.
+// To avoid extruding the shared parts twice, each line keeps half of the lineWidth distance to the border of its column.
+type honeycomb struct {
+	lineWidth    data.Micrometer
+	lineDistance data.Micrometer
+	degree       int
+	min, max     data.MicroPoint
+}
+
+// NewHoneycombPattern provides a honeycomb infill pattern.
+// The lineDistance is the distance of the lines of a linear pattern with the same density.
+// The width of the columns (which is half the width of a cell) is calculated from it so
+// that both use the same amount of material.
+// Min and max define the dimension of the model.
+func NewHoneycombPattern(lineWidth data.Micrometer, lineDistance data.Micrometer, min data.MicroPoint, max data.MicroPoint, degree int) Pattern {
+	return honeycomb{
+		lineWidth: lineWidth,
+		// Each column contains lines with the length of 4 sides per 3 sides of height.
+		lineDistance: lineDistance * 4 / 3,
+		degree:       degree,
+		min:          min,
+		max:          max,
+	}
+}
+
+// Fill implements the Pattern interface by generating the zig-zag lines, clipping them by the part and connecting them.
+func (p honeycomb) Fill(layerNr int, z data.Micrometer, part data.LayerPart) (data.Paths, error) {
+	if p.lineDistance <= 0 {
+		return nil, nil
+	}
+
+	// Generate the pattern in a rotated coordinate system.
+	bounds := data.Path{
+		p.min,
+		data.NewMicroPoint(p.max.X(), p.min.Y()),
+		p.max,
+		data.NewMicroPoint(p.min.X(), p.max.Y()),
+	}
+	bounds.Rotate(float64(p.degree))
+	min, max := bounds.Bounds()
+
+	lines := p.lines(min, max)
+	lines.Rotate(-float64(p.degree))
+
+	clipped, err := clipLines(part, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	return connectLines(part, clipped, p.lineDistance)
+}
+
+// lines generates the zig-zag lines of all columns between min and max.
+func (p honeycomb) lines(min, max data.MicroPoint) data.Paths {
+	distance := float64(p.lineDistance)
+	side := distance * 2 / math.Sqrt(3)
+	diagonalHeight := side / 2
+	period := 2*side + 2*diagonalHeight
+
+	inset := 0.0
+	if p.lineDistance > 2*p.lineWidth {
+		inset = float64(p.lineWidth) / 2
+	}
+
+	point := func(x, y float64) data.MicroPoint {
+		return data.NewMicroPoint(data.Micrometer(math.Round(x)), data.Micrometer(math.Round(y)))
+	}
+
+	var result data.Paths
+	firstColumn := int(math.Floor(float64(min.X()) / distance))
+	for column := firstColumn; float64(column)*distance <= float64(max.X()); column++ {
+		left := float64(column)*distance + inset
+		right := float64(column+1)*distance - inset
+
+		// The columns are aligned to the origin and each second one is offset by half a period.
+		offset := 0.0
+		if column%2 != 0 {
+			offset = period / 2
+		}
+		y := math.Floor((float64(min.Y())-offset)/period)*period + offset
+
+		var line data.Path
+		for ; y <= float64(max.Y()); y += period {
+			line = append(line,
+				point(left, y),
+				point(left, y+side),
+				point(right, y+side+diagonalHeight),
+				point(right, y+2*side+diagonalHeight),
+			)
+		}
+		line = append(line, point(left, y))
+
+		result = append(result, line)
+	}
+
+	return result
+}
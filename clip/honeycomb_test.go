@@ -0,0 +1,38 @@
+package clip_test
+
+import (
+	"testing"
+
+	"github.com/aligator/goslice/clip"
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/util/test"
+)
+
+func TestHoneycombPattern(t *testing.T) {
+	size := data.Micrometer(20000)
+	lineDistance := data.Micrometer(2000)
+	// Without a line width the lines of neighbouring columns touch each other, so the cells are regular hexagons.
+	pattern := clip.NewHoneycombPattern(0, lineDistance, data.NewMicroPoint(0, 0), data.NewMicroPoint(size, size), 0)
+
+	paths, err := pattern.Fill(0, 200, square(size))
+	test.Ok(t, err)
+
+	test.Assert(t, len(paths) > 0, "the part should be filled")
+	for _, path := range paths {
+		for _, p := range path {
+			test.Assert(t, p.X() >= 0 && p.X() <= size && p.Y() >= 0 && p.Y() <= size, "the point %v should be inside of the part", p)
+		}
+	}
+
+	// The hexagons consist of vertical lines and diagonals with 30° to the x-axis.
+	test.Equals(t, map[int]bool{30: true, 90: true, 150: true}, directions(paths, 1000))
+
+	// It should use about the same amount of material as a linear pattern.
+	straight := float64(size) * float64(size) / float64(lineDistance)
+	test.Assert(t, length(paths) > 0.9*straight && length(paths) < 1.5*straight, "unexpected length %v of the infill", length(paths))
+
+	// All layers are the same, so the cells form hexagonal prisms.
+	other, err := pattern.Fill(10, 2200, square(size))
+	test.Ok(t, err)
+	test.Assert(t, equalPaths(paths, other), "the pattern should not change with the height")
+}
@@ -0,0 +1,311 @@
+// This file provides the lightning infill which only supports the top layers.
+
+package clip
+
+import (
+	"math"
+	"sort"
+
+	clipper "github.com/aligator/go.clipper"
+	"github.com/aligator/goslice/data"
+)
+
+// lightningNode is one point of a lightning tree.
+// Its branch goes from the point to the parent. Roots have no parent and lie on the border of the infill area.
+type lightningNode struct {
+	point    data.MicroPoint
+	parent   *lightningNode
+	children int
+	removed  bool
+}
+
+// Lightning generates a tree-like infill which only supports the areas above it instead of filling the whole part.
+// In contrast to the patterns it depends on the layers above, so it has to be used layer by layer
+// from the top to the bottom of the model.
+//
+// The trees start at points below the areas which need support and grow to the border of the infill area
+// where they are carried by the perimeters. New points connect to an existing tree if it is nearer than the border.
+// On each layer below, the branches get shorter from their ends by the thickness of the layer,
+// so they form a slope of 45° below the supported areas and disappear after some layers.
+type Lightning struct {
+	lineWidth    data.Micrometer
+	lineDistance data.Micrometer
+
+	nodes []*lightningNode
+}
+
+// NewLightning provides a new lightning infill without any trees.
+// The lineDistance is the distance of the points which support the areas above.
+func NewLightning(lineWidth data.Micrometer, lineDistance data.Micrometer) *Lightning {
+	return &Lightning{
+		lineWidth:    lineWidth,
+		lineDistance: lineDistance,
+	}
+}
+
+// Layer grows the trees to the next layer below and returns the lines to print on it.
+// The area is the part of the layer which may contain infill and the overhang
+// is the part of it which has to support the layer above.
+// The branches get shorter by the thickness of the layer.
+func (l *Lightning) Layer(area []data.LayerPart, overhang []data.LayerPart, thickness data.Micrometer) (data.Paths, error) {
+	if l.lineDistance <= 0 {
+		return nil, nil
+	}
+
+	l.prune(thickness)
+	l.reroot(area)
+	l.support(area, overhang)
+
+	var lines data.Paths
+	for _, node := range l.nodes {
+		if node.parent != nil {
+			lines = append(lines, data.Path{node.point, node.parent.point})
+		}
+	}
+
+	var result data.Paths
+	for _, part := range area {
+		clipped, err := clipLines(part, lines)
+		if err != nil {
+			return nil, err
+		}
+
+		connected, err := connectLines(part, clipped, l.lineWidth)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, connected...)
+	}
+
+	return result, nil
+}
+
+// prune shortens all branches which end in a leaf by the step.
+// Nodes which are reached are removed, so the remaining length continues on the branch of the parent
+// if it has no other children.
+func (l *Lightning) prune(step data.Micrometer) {
+	var leaves []*lightningNode
+	for _, node := range l.nodes {
+		if node.children == 0 {
+			leaves = append(leaves, node)
+		}
+	}
+
+	for _, leaf := range leaves {
+		remaining := float64(step)
+		for {
+			if leaf.parent == nil {
+				leaf.removed = true
+				break
+			}
+
+			direction := leaf.parent.point.Sub(leaf.point)
+			length := float64(direction.Size())
+			if length > remaining {
+				leaf.point = leaf.point.Add(scale(direction, remaining/length))
+				break
+			}
+
+			remaining -= length
+			leaf.removed = true
+			leaf.parent.children--
+			if leaf.parent.children > 0 {
+				break
+			}
+			leaf = leaf.parent
+		}
+	}
+
+	nodes := l.nodes[:0]
+	for _, node := range l.nodes {
+		if !node.removed {
+			nodes = append(nodes, node)
+		}
+	}
+	l.nodes = nodes
+}
+
+// reroot connects the roots which are not at the border of the area anymore to the nearest point of it.
+// This happens if the area gets bigger on the lower layers.
+func (l *Lightning) reroot(area []data.LayerPart) {
+	for _, node := range l.nodes {
+		if node.parent != nil || !isInside(area, node.point) {
+			continue
+		}
+
+		border, distance := nearestBorderPoint(area, node.point)
+		if distance <= float64(l.lineWidth) {
+			continue
+		}
+
+		root := &lightningNode{point: border, children: 1}
+		node.parent = root
+		l.nodes = append(l.nodes, root)
+	}
+}
+
+// support adds a point for each lineDistance of the overhang which is not already supported by a tree.
+// The points near the border are added first, so the points further inside can connect to their trees.
+func (l *Lightning) support(area []data.LayerPart, overhang []data.LayerPart) {
+	type candidate struct {
+		point          data.MicroPoint
+		border         data.MicroPoint
+		borderDistance float64
+	}
+
+	var candidates []candidate
+	for _, point := range l.sample(overhang) {
+		if !isInside(area, point) {
+			continue
+		}
+
+		border, distance := nearestBorderPoint(area, point)
+		candidates = append(candidates, candidate{point, border, distance})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].borderDistance < candidates[j].borderDistance
+	})
+
+	for _, c := range candidates {
+		if l.isSupported(c.point) {
+			continue
+		}
+
+		parent, distance := l.nearestNode(c.point)
+		if parent == nil || distance >= c.borderDistance {
+			parent = &lightningNode{point: c.border}
+			l.nodes = append(l.nodes, parent)
+		}
+
+		parent.children++
+		l.nodes = append(l.nodes, &lightningNode{point: c.point, parent: parent})
+	}
+}
+
+// sample returns the points of a grid with the lineDistance which are inside of the parts.
+// The grid is aligned to the origin, so the points are the same on all layers.
+// Parts which are too small to contain any grid point get one point in the middle of their bounding box.
+func (l *Lightning) sample(parts []data.LayerPart) []data.MicroPoint {
+	var result []data.MicroPoint
+	for _, part := range parts {
+		min, max := part.Outline().Bounds()
+		found := false
+		for x := ceilTo(min.X(), l.lineDistance); x <= max.X(); x += l.lineDistance {
+			for y := ceilTo(min.Y(), l.lineDistance); y <= max.Y(); y += l.lineDistance {
+				point := data.NewMicroPoint(x, y)
+				if isInside([]data.LayerPart{part}, point) {
+					result = append(result, point)
+					found = true
+				}
+			}
+		}
+
+		if !found {
+			result = append(result, data.NewMicroPoint((min.X()+max.X())/2, (min.Y()+max.Y())/2))
+		}
+	}
+
+	return result
+}
+
+// isSupported checks if a branch is nearer to the point than half of the lineDistance.
+func (l *Lightning) isSupported(point data.MicroPoint) bool {
+	maxDistance := float64(l.lineDistance) / 2
+	for _, node := range l.nodes {
+		if node.parent == nil {
+			continue
+		}
+
+		if distance(point, nearestSegmentPoint(point, node.point, node.parent.point)) < maxDistance {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nearestNode returns the node which is nearest to the point and its distance.
+// If there are no nodes, nil is returned.
+func (l *Lightning) nearestNode(point data.MicroPoint) (*lightningNode, float64) {
+	var nearest *lightningNode
+	nearestDistance := math.Inf(1)
+	for _, node := range l.nodes {
+		if d := distance(point, node.point); d < nearestDistance {
+			nearest, nearestDistance = node, d
+		}
+	}
+
+	return nearest, nearestDistance
+}
+
+// isInside checks if the point is inside of one of the parts or on its border.
+func isInside(parts []data.LayerPart, point data.MicroPoint) bool {
+	p := clipperPoint(point)
+	for _, part := range parts {
+		if clipper.PointInPolygon(p, clipperPath(part.Outline())) == 0 {
+			continue
+		}
+
+		inHole := false
+		for _, hole := range part.Holes() {
+			if clipper.PointInPolygon(p, clipperPath(hole)) == 1 {
+				inHole = true
+				break
+			}
+		}
+
+		if !inHole {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nearestBorderPoint returns the point on the outlines and holes of the parts which is nearest to the given point
+// and its distance.
+func nearestBorderPoint(parts []data.LayerPart, point data.MicroPoint) (data.MicroPoint, float64) {
+	nearest := point
+	nearestDistance := math.Inf(1)
+	for _, part := range parts {
+		for _, path := range append(data.Paths{part.Outline()}, part.Holes()...) {
+			for i := range path {
+				candidate := nearestSegmentPoint(point, path[i], path[(i+1)%len(path)])
+				if d := distance(point, candidate); d < nearestDistance {
+					nearest, nearestDistance = candidate, d
+				}
+			}
+		}
+	}
+
+	return nearest, nearestDistance
+}
+
+// nearestSegmentPoint returns the point on the line segment from a to b which is nearest to the given point.
+func nearestSegmentPoint(point, a, b data.MicroPoint) data.MicroPoint {
+	ab := b.Sub(a)
+	length2 := float64(ab.Size2())
+	if length2 == 0 {
+		return a
+	}
+
+	t := float64(data.DotProduct(point.Sub(a), ab)) / length2
+	t = math.Max(0, math.Min(1, t))
+	return a.Add(scale(ab, t))
+}
+
+// scale multiplies both coordinates of the point with the factor.
+func scale(p data.MicroPoint, factor float64) data.MicroPoint {
+	return data.NewMicroPoint(data.Micrometer(math.Round(float64(p.X())*factor)), data.Micrometer(math.Round(float64(p.Y())*factor)))
+}
+
+// distance returns the distance between two points.
+func distance(a, b data.MicroPoint) float64 {
+	return math.Sqrt(float64(a.Sub(b).Size2()))
+}
+
+// ceilTo rounds the value up to the next multiple of step.
+func ceilTo(value, step data.Micrometer) data.Micrometer {
+	return data.Micrometer(math.Ceil(float64(value)/float64(step))) * step
+}
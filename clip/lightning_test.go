@@ -0,0 +1,81 @@
+package clip_test
+
+import (
+	"testing"
+
+	"github.com/aligator/goslice/clip"
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/util/test"
+)
+
+func TestLightning(t *testing.T) {
+	area := []data.LayerPart{square(20000)}
+	lightning := clip.NewLightning(400, 4000)
+
+	// Nothing to support.
+	paths, err := lightning.Layer(area, nil, 1000)
+	test.Ok(t, err)
+	test.Equals(t, 0, len(paths))
+
+	// Support a small area in the middle.
+	overhang := []data.LayerPart{data.NewBasicLayerPart(data.Path{
+		data.NewMicroPoint(7000, 7000),
+		data.NewMicroPoint(13000, 7000),
+		data.NewMicroPoint(13000, 13000),
+		data.NewMicroPoint(7000, 13000),
+	}, nil)}
+	paths, err = lightning.Layer(area, overhang, 1000)
+	test.Ok(t, err)
+	test.Assert(t, len(paths) > 0, "the overhang should be supported")
+
+	// The trees reach from the supported points to the border.
+	hasBorder := false
+	for _, path := range paths {
+		for _, point := range path {
+			test.Assert(t, point.X() >= 0 && point.X() <= 20000 && point.Y() >= 0 && point.Y() <= 20000, "the lines should be inside of the area")
+			if point.X() == 0 || point.X() == 20000 || point.Y() == 0 || point.Y() == 20000 {
+				hasBorder = true
+			}
+		}
+	}
+	test.Assert(t, hasBorder, "the trees should be connected to the border")
+
+	// The four points of the overhang form one tree.
+	// Only the first one is connected to the border, all others to their nearest point.
+	test.Equals(t, 8000.0+3*4000, length(paths))
+
+	// The branches get shorter on each layer below until they disappear.
+	previous := length(paths)
+	for i := 0; i < 20; i++ {
+		paths, err = lightning.Layer(area, nil, 1000)
+		test.Ok(t, err)
+		test.Assert(t, length(paths) < previous || length(paths) == 0, "the trees should get shorter on layer %v", i)
+		previous = length(paths)
+	}
+	test.Equals(t, 0, len(paths))
+}
+
+func TestLightningThickness(t *testing.T) {
+	area := []data.LayerPart{square(20000)}
+	overhang := []data.LayerPart{data.NewBasicLayerPart(data.Path{
+		data.NewMicroPoint(7000, 7000),
+		data.NewMicroPoint(13000, 7000),
+		data.NewMicroPoint(13000, 13000),
+		data.NewMicroPoint(7000, 13000),
+	}, nil)}
+
+	// The branches get shorter by the thickness of the layer.
+	shortened := func(thickness data.Micrometer) float64 {
+		lightning := clip.NewLightning(400, 4000)
+		paths, err := lightning.Layer(area, overhang, 200)
+		test.Ok(t, err)
+		previous := length(paths)
+
+		paths, err = lightning.Layer(area, nil, thickness)
+		test.Ok(t, err)
+		return previous - length(paths)
+	}
+
+	test.Assert(t, shortened(100) > 0, "the trees should get shorter")
+	test.Equals(t, 3*shortened(100), shortened(300))
+}
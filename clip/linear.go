@@ -35,7 +35,7 @@ func NewLinearPattern(lineWidth data.Micrometer, lineDistance data.Micrometer, m
 }
 
 // Fill implements the Pattern interface by using simple linear lines as infill.
-func (p linear) Fill(layerNr int, part data.LayerPart) (data.Paths, error) {
+func (p linear) Fill(layerNr int, z data.Micrometer, part data.LayerPart) (data.Paths, error) {
 	rotation := float64(p.degree)
 
 	// for rectlinear fill patterns rotate each 2nd layer by 90 degree.
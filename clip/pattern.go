@@ -0,0 +1,101 @@
+// This file provides helpers which are shared by the infill patterns.
+
+package clip
+
+import (
+	"errors"
+
+	clipper "github.com/aligator/go.clipper"
+	"github.com/aligator/goslice/data"
+)
+
+// clipLines clips the open paths by the outline and the holes of the part.
+// The paths may be cut into several pieces.
+func clipLines(part data.LayerPart, lines data.Paths) (data.Paths, error) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	cl := clipper.NewClipper(clipper.IoNone)
+	cl.AddPath(clipperPath(part.Outline()), clipper.PtClip, true)
+	cl.AddPaths(clipperPaths(part.Holes()), clipper.PtClip, true)
+	cl.AddPaths(clipperPaths(lines), clipper.PtSubject, false)
+
+	tree, ok := cl.Execute2(clipper.CtIntersection, clipper.PftEvenOdd, clipper.PftEvenOdd)
+	if !ok {
+		return nil, errors.New("could not clip the infill lines by the part")
+	}
+
+	var result data.Paths
+	for _, child := range tree.Childs() {
+		if len(child.Contour()) < 2 {
+			continue
+		}
+		result = append(result, microPath(child.Contour(), false))
+	}
+
+	return result, nil
+}
+
+// connectLines sorts the paths so that each one starts next to the end of the previous one.
+// Paths are reversed if needed. If the gap to the next path is not longer than maxDistance
+// and does not cross the perimeter of the part, both are joined into one path so that the gap is printed, too.
+func connectLines(part data.LayerPart, lines data.Paths, maxDistance data.Micrometer) (data.Paths, error) {
+	if len(lines) == 0 {
+		return lines, nil
+	}
+
+	cl := NewClipper()
+
+	isUsed := make([]bool, len(lines))
+	isUsed[0] = true
+	current := append(data.Path{}, lines[0]...)
+	var result data.Paths
+
+	for used := 1; used < len(lines); used++ {
+		end := current[len(current)-1]
+
+		// Find the path with the nearest start or end point.
+		best := -1
+		bestReverse := false
+		var bestDistance data.Micrometer
+		for i, line := range lines {
+			if isUsed[i] {
+				continue
+			}
+
+			if distance := line[0].Sub(end).Size2(); best == -1 || distance < bestDistance {
+				best, bestReverse, bestDistance = i, false, distance
+			}
+			if distance := line[len(line)-1].Sub(end).Size2(); distance < bestDistance {
+				best, bestReverse, bestDistance = i, true, distance
+			}
+		}
+
+		isUsed[best] = true
+		next := append(data.Path{}, lines[best]...)
+		if bestReverse {
+			for i, j := 0, len(next)-1; i < j; i, j = i+1, j-1 {
+				next[i], next[j] = next[j], next[i]
+			}
+		}
+
+		connect := bestDistance <= maxDistance*maxDistance
+		if connect {
+			isCrossing, ok := cl.IsCrossingPerimeter([]data.LayerPart{part}, data.Path{end, next[0]})
+			if !ok {
+				return nil, errors.New("could not check if the connection of two infill lines crosses the perimeter")
+			}
+			connect = !isCrossing
+		}
+
+		if connect {
+			current = append(current, next...)
+		} else {
+			result = append(result, current)
+			current = next
+		}
+	}
+
+	return append(result, current), nil
+}
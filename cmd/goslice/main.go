@@ -1,8 +1,11 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"log"
 	"os"
 
 	"github.com/aligator/goslice"
@@ -14,6 +17,11 @@ import (
 var Version = "unknown development version"
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "inspect" {
+		inspect()
+		return
+	}
+
 	o := data.ParseFlags()
 
 	if o.GoSlice.PrintVersion {
@@ -22,11 +30,19 @@ func main() {
 	}
 
 	if o.GoSlice.InputFilePath == "" {
-		_, _ = fmt.Fprintf(os.Stderr, "the STL_FILE path has to be specified\n")
+		_, _ = fmt.Fprintf(os.Stderr, "the MODEL_FILE path has to be specified\n")
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	for _, file := range o.GoSlice.InputFiles {
+		if file.Path == "" {
+			_, _ = fmt.Fprintf(os.Stderr, "there are more --instances than MODEL_FILE paths\n")
+			flag.Usage()
+			os.Exit(1)
+		}
+	}
+
 	p := goslice.NewGoSlice(o)
 	err := p.Process()
 
@@ -36,6 +52,43 @@ func main() {
 	}
 }
 
+// inspect runs the inspect subcommand which prints the diagnostics of a model file:
+// goslice inspect MODEL_FILE [--json] [flags]
+func inspect() {
+	// Remove the subcommand so that only the flags and the file path are parsed.
+	os.Args = append(os.Args[:1], os.Args[2:]...)
+	jsonOutput := flag.Bool("json", false, "Print the diagnostics as JSON.")
+	o := data.ParseFlags()
+
+	if flag.NArg() != 1 {
+		_, _ = fmt.Fprintf(os.Stderr, "exactly one MODEL_FILE path has to be specified\n")
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of goslice inspect: goslice inspect MODEL_FILE [--json] [flags]\n")
+		os.Exit(1)
+	}
+
+	// Only the diagnostics should be printed.
+	o.GoSlice.Logger = log.New(ioutil.Discard, "", 0)
+
+	p := goslice.NewGoSlice(o)
+	report, err := p.Inspect()
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error while inspecting file:", err)
+		os.Exit(2)
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "error while writing the diagnostics:", err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	fmt.Print(report)
+}
+
 func printVersion(w io.Writer) {
 	str := fmt.Sprintf("GoSlice %s", Version)
 	_, _ = w.Write([]byte(str))
@@ -181,6 +181,27 @@ func (p Path) Rotate(degree float64) {
 	}
 }
 
+// StartNearest returns a copy of the closed path which starts at the point which is closest to the given point.
+// The order of the points is not changed.
+func (p Path) StartNearest(point MicroPoint) Path {
+	if len(p) == 0 {
+		return Path{}
+	}
+
+	nearest := 0
+	nearestDistance := p[0].Sub(point).Size2()
+	for i, other := range p {
+		if distance := other.Sub(point).Size2(); distance < nearestDistance {
+			nearest = i
+			nearestDistance = distance
+		}
+	}
+
+	result := make(Path, 0, len(p))
+	result = append(result, p[nearest:]...)
+	return append(result, p[:nearest]...)
+}
+
 func (p Path) Take(i int) (x, y float64) {
 	point := p[i]
 	return float64(point.X()), float64(point.Y())
@@ -270,6 +291,12 @@ type LayerPart interface {
 // Holes have to be clockwise and outlines counter clockwise.
 type Layer interface {
 	Polygons() Paths
+
+	// Z returns the height of the top of the layer, which is also the height at which the model was sliced.
+	Z() Micrometer
+
+	// Thickness returns the thickness of the layer.
+	Thickness() Micrometer
 }
 
 // PartitionedLayer represents one layer with separated layer parts.
@@ -278,6 +305,13 @@ type Layer interface {
 type PartitionedLayer interface {
 	LayerParts() []LayerPart
 
+	// Z returns the height of the top of the layer, which is also the height of the nozzle while printing it.
+	Z() Micrometer
+
+	// Thickness returns the thickness of the layer.
+	// Each layer can have a different thickness.
+	Thickness() Micrometer
+
 	// Attributes can be any additional data, referenced by a key.
 	// Note that you have to know what type the attribute has to
 	// use proper type assertion.
@@ -320,13 +354,18 @@ func (l basicLayerPart) Attributes() map[string]interface{} {
 }
 
 type partitionedLayer struct {
-	parts []LayerPart
+	parts     []LayerPart
+	z         Micrometer
+	thickness Micrometer
 }
 
-// NewPartitionedLayer returns a new simple PartitionedLayer which just contains several LayerParts.
-func NewPartitionedLayer(parts []LayerPart) PartitionedLayer {
+// NewPartitionedLayer returns a new simple PartitionedLayer which just contains several LayerParts
+// and the height of the layer.
+func NewPartitionedLayer(parts []LayerPart, z, thickness Micrometer) PartitionedLayer {
 	return partitionedLayer{
-		parts: parts,
+		parts:     parts,
+		z:         z,
+		thickness: thickness,
 	}
 }
 
@@ -334,6 +373,14 @@ func (p partitionedLayer) LayerParts() []LayerPart {
 	return p.parts
 }
 
+func (p partitionedLayer) Z() Micrometer {
+	return p.z
+}
+
+func (p partitionedLayer) Thickness() Micrometer {
+	return p.thickness
+}
+
 func (p partitionedLayer) Attributes() map[string]interface{} {
 	return nil
 }
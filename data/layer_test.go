@@ -167,6 +167,37 @@ func TestPathBounds(t *testing.T) {
 	}
 }
 
+func TestPathStartNearest(t *testing.T) {
+	square := data.Path{
+		data.NewMicroPoint(0, 0),
+		data.NewMicroPoint(100, 0),
+		data.NewMicroPoint(100, 100),
+		data.NewMicroPoint(0, 100),
+	}
+
+	var testCases = []struct {
+		point    data.MicroPoint
+		expected data.Path
+	}{
+		{point: data.NewMicroPoint(-10, -10), expected: square},
+		{point: data.NewMicroPoint(90, 110), expected: data.Path{
+			data.NewMicroPoint(100, 100),
+			data.NewMicroPoint(0, 100),
+			data.NewMicroPoint(0, 0),
+			data.NewMicroPoint(100, 0),
+		}},
+	}
+
+	for i, testCase := range testCases {
+		t.Log("testCase", i)
+		result := square.StartNearest(testCase.point)
+		test.Equals(t, len(testCase.expected), len(result))
+		test.Equals(t, testCase.expected, result, pathComparer())
+	}
+
+	test.Equals(t, 0, len(data.Path{}.StartNearest(data.NewMicroPoint(0, 0))))
+}
+
 func TestPathsBounds(t *testing.T) {
 	var testCases = []struct {
 		toTest      data.Paths
@@ -0,0 +1,101 @@
+// This file provides a simple affine transformation matrix for 3d space.
+
+package data
+
+import "math"
+
+// Matrix is an affine transformation in 3d space.
+// It is stored row major and is applied to column vectors (p' = M * p).
+// So the translation is stored in the last column.
+type Matrix [4][4]float64
+
+// IdentityMatrix returns a matrix which does not change anything.
+func IdentityMatrix() Matrix {
+	return Matrix{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Mul returns the product of both matrices (m * o).
+// Applying the result is the same as first applying o and then m.
+func (m Matrix) Mul(o Matrix) Matrix {
+	var result Matrix
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			for i := 0; i < 4; i++ {
+				result[row][col] += m[row][i] * o[i][col]
+			}
+		}
+	}
+	return result
+}
+
+// Apply transforms the given point.
+func (m Matrix) Apply(x, y, z float64) (float64, float64, float64) {
+	return m[0][0]*x + m[0][1]*y + m[0][2]*z + m[0][3],
+		m[1][0]*x + m[1][1]*y + m[1][2]*z + m[1][3],
+		m[2][0]*x + m[2][1]*y + m[2][2]*z + m[2][3]
+}
+
+// ScaleMatrix returns a matrix which scales by the given factors.
+// Negative factors mirror along the axis.
+func ScaleMatrix(x, y, z float64) Matrix {
+	m := IdentityMatrix()
+	m[0][0] = x
+	m[1][1] = y
+	m[2][2] = z
+	return m
+}
+
+// TranslationMatrix returns a matrix which moves by the given offset.
+func TranslationMatrix(x, y, z float64) Matrix {
+	m := IdentityMatrix()
+	m[0][3] = x
+	m[1][3] = y
+	m[2][3] = z
+	return m
+}
+
+// RotationXMatrix returns a matrix which rotates counterclockwise around the x axis.
+func RotationXMatrix(degrees float64) Matrix {
+	sin, cos := math.Sincos(ToRadians(degrees))
+	return Matrix{
+		{1, 0, 0, 0},
+		{0, cos, -sin, 0},
+		{0, sin, cos, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// RotationYMatrix returns a matrix which rotates counterclockwise around the y axis.
+func RotationYMatrix(degrees float64) Matrix {
+	sin, cos := math.Sincos(ToRadians(degrees))
+	return Matrix{
+		{cos, 0, sin, 0},
+		{0, 1, 0, 0},
+		{-sin, 0, cos, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// RotationZMatrix returns a matrix which rotates counterclockwise around the z axis.
+func RotationZMatrix(degrees float64) Matrix {
+	sin, cos := math.Sincos(ToRadians(degrees))
+	return Matrix{
+		{cos, -sin, 0, 0},
+		{sin, cos, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Determinant returns the determinant of the linear part (without the translation).
+// It is negative if the matrix mirrors.
+func (m Matrix) Determinant() float64 {
+	return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+}
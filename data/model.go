@@ -36,4 +36,73 @@ type OptimizedModel interface {
 
 	OptimizedFace(index int) OptimizedFace
 	SaveDebugSTL(filename string) error
+
+	// Shells returns each group of faces which are connected through their touching faces as separate model.
+	Shells() []Model
+
+	// RepairReport returns which errors of the model were found and fixed while optimizing it.
+	RepairReport() RepairReport
+}
+
+// basicFace is a simple implementation of Face.
+type basicFace struct {
+	points [3]MicroVec3
+}
+
+// NewBasicFace returns a new, simple Face.
+func NewBasicFace(points [3]MicroVec3) Face {
+	return basicFace{
+		points: points,
+	}
+}
+
+func (f basicFace) Points() [3]MicroVec3 {
+	return f.points
+}
+
+// basicModel is a simple implementation of Model.
+// The bounds are calculated once on creation.
+type basicModel struct {
+	faces    []Face
+	min, max MicroVec3
+}
+
+// NewBasicModel returns a new, simple Model containing the given faces.
+func NewBasicModel(faces []Face) Model {
+	m := basicModel{
+		faces: faces,
+		min:   NewMicroVec3(0, 0, 0),
+		max:   NewMicroVec3(0, 0, 0),
+	}
+
+	for i, face := range faces {
+		for j, p := range face.Points() {
+			if i == 0 && j == 0 {
+				m.min = p.Copy()
+				m.max = p.Copy()
+				continue
+			}
+
+			m.min = NewMicroVec3(Min(m.min.X(), p.X()), Min(m.min.Y(), p.Y()), Min(m.min.Z(), p.Z()))
+			m.max = NewMicroVec3(Max(m.max.X(), p.X()), Max(m.max.Y(), p.Y()), Max(m.max.Z(), p.Z()))
+		}
+	}
+
+	return &m
+}
+
+func (m basicModel) FaceCount() int {
+	return len(m.faces)
+}
+
+func (m basicModel) Face(index int) Face {
+	return m.faces[index]
+}
+
+func (m basicModel) Min() MicroVec3 {
+	return m.min.Copy()
+}
+
+func (m basicModel) Max() MicroVec3 {
+	return m.max.Copy()
 }
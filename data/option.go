@@ -88,6 +88,20 @@ func NewDefaultFanSpeedOptions() FanSpeedOptions {
 	return fo
 }
 
+// SpeedAt returns the fan speed which is set at the given layer.
+// It is 0 before the first entry of the LayerToSpeedLUT.
+func (f FanSpeedOptions) SpeedAt(layerNr int) int {
+	speed := 0
+	lastLayer := -1
+	for layer, layerSpeed := range f.LayerToSpeedLUT {
+		if layer <= layerNr && layer > lastLayer {
+			speed = layerSpeed
+			lastLayer = layer
+		}
+	}
+	return speed
+}
+
 func (f FanSpeedOptions) Type() string {
 	return "FanSpeedOptions"
 }
@@ -215,6 +229,19 @@ type PrintOptions struct {
 	// LayerThickness is the thickness for all but the first layer.
 	LayerThickness Micrometer
 
+	// AdaptiveLayers enables layers with a variable thickness instead of the LayerThickness.
+	// Flat slopes get thin layers and steep walls thick ones.
+	// The first layer still uses the InitialLayerThickness.
+	AdaptiveLayers bool
+
+	// MinLayerThickness is the thickness of the thinnest adaptive layers.
+	// It is used for nearly flat slopes.
+	MinLayerThickness Micrometer
+
+	// MaxLayerThickness is the thickness of the thickest adaptive layers.
+	// It is used for vertical walls.
+	MaxLayerThickness Micrometer
+
 	// InsetCount is the number of perimeters.
 	InsetCount int
 
@@ -234,15 +261,28 @@ type PrintOptions struct {
 	// InfillZigZig sets if the infill should use connected lines in zig zag form.
 	InfillZigZag bool
 
+	// InfillPattern is the pattern used for the internal infill.
+	InfillPattern InfillPattern
+
+	// TopBottomPattern is the pattern used for the top and bottom layers.
+	// Only the TopBottomPatterns are supported, all others are handled as linear.
+	TopBottomPattern InfillPattern
+
 	// NumberBottomLayers is the amount of layers the bottom layers should grow into the model.
 	NumberBottomLayers int
 
 	// NumberBottomLayers is the amount of layers the bottom layers should grow into the model.
 	NumberTopLayers int
 
+	// SpiralVase prints all layers above the NumberBottomLayers as one continuous spiral of the outer perimeter
+	// with a steadily rising Z. These layers get no inner perimeters, no infill and no top layers.
+	SpiralVase bool
+
 	Support SupportOptions
 
 	BrimSkirt BrimSkirtOptions
+
+	Ironing IroningOptions
 }
 
 // FilamentOptions contains all Filament specific GoSlice options.
@@ -315,6 +355,104 @@ type BrimSkirtOptions struct {
 	BrimCount int
 }
 
+// InfillPattern is the pattern used for the internal infill.
+type InfillPattern string
+
+const (
+	// InfillPatternLinear uses parallel lines which are rotated by 90° on each layer.
+	InfillPatternLinear InfillPattern = "linear"
+
+	// InfillPatternGyroid uses the wave lines of a gyroid which change steadily from layer to layer.
+	InfillPatternGyroid InfillPattern = "gyroid"
+
+	// InfillPatternGrid uses lines in two directions which cross each other on each layer.
+	InfillPatternGrid InfillPattern = "grid"
+
+	// InfillPatternTriangles uses lines in three directions which form triangles on each layer.
+	InfillPatternTriangles InfillPattern = "triangles"
+
+	// InfillPatternCubic uses lines in three directions which move with the height, so they form cubes.
+	InfillPatternCubic InfillPattern = "cubic"
+
+	// InfillPatternHoneycomb uses zig-zag lines which form hexagonal cells.
+	InfillPatternHoneycomb InfillPattern = "honeycomb"
+
+	// InfillPatternConcentric uses loops which follow the outline of the parts.
+	InfillPatternConcentric InfillPattern = "concentric"
+
+	// InfillPatternLightning uses trees which grow downwards only below the top layers to support them.
+	InfillPatternLightning InfillPattern = "lightning"
+)
+
+// InfillPatterns contains all supported infill patterns.
+var InfillPatterns = []InfillPattern{
+	InfillPatternLinear,
+	InfillPatternGyroid,
+	InfillPatternGrid,
+	InfillPatternTriangles,
+	InfillPatternCubic,
+	InfillPatternHoneycomb,
+	InfillPatternConcentric,
+	InfillPatternLightning,
+}
+
+// TopBottomPatterns contains the infill patterns which can fill the solid top and bottom layers.
+var TopBottomPatterns = []InfillPattern{
+	InfillPatternLinear,
+	InfillPatternConcentric,
+}
+
+func (p InfillPattern) String() string {
+	return string(p)
+}
+
+func (p *InfillPattern) Set(s string) error {
+	return p.set(s, InfillPatterns)
+}
+
+// set sets the pattern if it is one of the supported patterns.
+func (p *InfillPattern) set(s string, supported []InfillPattern) error {
+	for _, pattern := range supported {
+		if string(pattern) == s {
+			*p = pattern
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown infill pattern %q, supported are %v", s, supported)
+}
+
+func (p InfillPattern) Type() string {
+	return "InfillPattern"
+}
+
+// topBottomPattern is a flag value which only accepts the TopBottomPatterns.
+type topBottomPattern struct {
+	*InfillPattern
+}
+
+func (p topBottomPattern) Set(s string) error {
+	return p.set(s, TopBottomPatterns)
+}
+
+// IroningOptions contains all options for ironing the top surfaces.
+type IroningOptions struct {
+	// Enabled enables a second pass with low flow over the topmost surfaces to smooth them.
+	Enabled bool
+
+	// Spacing is the distance between the ironing lines.
+	Spacing Millimeter
+
+	// FlowPercent is the flow used for ironing in percent of the normal flow.
+	FlowPercent int
+
+	// Speed is the speed used for ironing in mm/s.
+	Speed Millimeter
+
+	// Angle is the rotation of the ironing lines.
+	Angle int
+}
+
 // FanSpeedOptions used to control fan speed at given layers.
 type FanSpeedOptions struct {
 	LayerToSpeedLUT map[int]int
@@ -328,6 +466,20 @@ type PrinterOptions struct {
 	// Center is the point where the model is finally placed.
 	Center MicroVec3
 
+	// BedWidth and BedDepth are the size of the bed in x and y direction.
+	// It is expected that the Center is in the middle of the bed.
+	// 0 disables the check if the print fits onto the bed in this direction.
+	BedWidth Millimeter
+	BedDepth Millimeter
+
+	// BedHeight is the max height which can be printed.
+	// 0 disables the check of the height.
+	BedHeight Millimeter
+
+	// CircularBed indicates a round bed (e.g. of a delta printer) around the Center.
+	// Its diameter is the BedWidth and the BedDepth is ignored.
+	CircularBed bool
+
 	// ForceSafeStartStopGCode toggles enforcing setting temps at beginning/end of print.
 	ForceSafeStartStopGCode bool
 
@@ -341,20 +493,78 @@ type PrinterOptions struct {
 	EndGCode GCodeHunk
 }
 
+// TransformOptions contains all options to transform the model before slicing.
+// The transformations are applied in the order scale, mirror, rotate x, rotate y, rotate z.
+// Afterwards the model is dropped onto the bed.
+type TransformOptions struct {
+	// Scale is the factor to scale the model with (1 = 100%).
+	Scale float64
+
+	// RotateX, RotateY and RotateZ are the counterclockwise rotations around the axes in degree.
+	RotateX float64
+	RotateY float64
+	RotateZ float64
+
+	// MirrorX, MirrorY and MirrorZ mirror the model by negating the coordinates of the axis.
+	MirrorX bool
+	MirrorY bool
+	MirrorZ bool
+
+	// LayFlat rotates the model automatically after the other transformations so that
+	// it has the most contact with the bed and the least overhangs (see Print.Support.ThresholdAngle).
+	LayFlat bool
+
+	// TranslateX and TranslateY move the model away from the Printer.Center.
+	TranslateX Millimeter
+	TranslateY Millimeter
+}
+
+// ArrangeOptions contains all options to place several models on the bed.
+type ArrangeOptions struct {
+	// Distance is the min distance between the footprints of two models.
+	// The brim of both models is added to it.
+	Distance Millimeter
+
+	// SplitShells places all disconnected shells of a model separately.
+	// Shells inside the bounding box of another shell stay with it.
+	SplitShells bool
+}
+
+// InputFile is a model file which should be printed.
+type InputFile struct {
+	// Path is the path to the model file.
+	Path string
+
+	// Instances is the amount of copies to print. Values below 1 are treated as 1.
+	Instances int
+}
+
 // GoSliceOptions contains all options related to GoSlice itself.
 type GoSliceOptions struct {
 	// PrintVersion indicates if the GoSlice version should be printed.
 	PrintVersion bool
 
-	// InputFilePath specifies the path to the input stl file.
+	// InputFilePath specifies the path to the input model file.
+	// It is only used if InputFiles is empty.
 	InputFilePath string
 
+	// InputFiles specifies several model files which are placed together on the bed.
+	InputFiles []InputFile
+
 	// OutputFilePath specifies the path to the output gcode file.
 	OutputFilePath string
 
 	// Logger can be used to redirect the log output to anything you want.
 	// All output in GoSlice just calls this logger.
 	Logger *log.Logger
+
+	// Progress is called with structured progress information, e.g. to render a progress bar.
+	// It has to be set before creating GoSlice and may be nil.
+	Progress ProgressFunc
+
+	// Concurrency is the maximum amount of goroutines used to process the layers.
+	// 0 means one goroutine per cpu and 1 disables the concurrent processing.
+	Concurrency int
 }
 
 // SlicingOptions contains all options related to slice a model.
@@ -371,15 +581,25 @@ type SlicingOptions struct {
 	// FinishPolygonSnapDistance is the max distance between start end endpoint of
 	// a polygon used to check if a open polygon can be closed.
 	FinishPolygonSnapDistance Micrometer
+
+	// RepairMesh enables the repair of the model by the optimizer.
+	// It removes degenerate faces, makes the orientation of the faces consistent and fills holes.
+	RepairMesh bool
 }
 
 // Options contains all GoSlice options.
 type Options struct {
-	Slicing  SlicingOptions
-	Printer  PrinterOptions
-	Filament FilamentOptions
-	Print    PrintOptions
-	GoSlice  GoSliceOptions
+	Slicing   SlicingOptions
+	Printer   PrinterOptions
+	Filament  FilamentOptions
+	Print     PrintOptions
+	Transform TransformOptions
+	Arrange   ArrangeOptions
+	GoSlice   GoSliceOptions
+
+	// Overrides change some options only between specific heights.
+	// Use ForHeight to get the options of a layer.
+	Overrides HeightOverrides
 }
 
 func (o Options) SetHasHeatedBed(val bool) Options {
@@ -387,6 +607,17 @@ func (o Options) SetHasHeatedBed(val bool) Options {
 	return o
 }
 
+// InfillLineDistance calculates the distance between the lines of a linear infill from Print.InfillPercent.
+// Other infill patterns use it to get the same density.
+func (o Options) InfillLineDistance() Micrometer {
+	// TODO: the calculation of the percentage is currently very basic and may not be correct.
+	mm10 := Millimeter(10).ToMicrometer()
+	linesPer10mmFor100Percent := mm10 / o.Printer.ExtrusionWidth
+	linesPer10mmForInfillPercent := float64(linesPer10mmFor100Percent) * float64(o.Print.InfillPercent) / 100.0
+
+	return Micrometer(float64(mm10) / linesPer10mmForInfillPercent)
+}
+
 func DefaultOptions() Options {
 	return Options{
 		Slicing: SlicingOptions{
@@ -401,14 +632,19 @@ func DefaultOptions() Options {
 			MoveSpeed:                              150,
 			InitialLayerThickness:                  200,
 			LayerThickness:                         200,
+			MinLayerThickness:                      100,
+			MaxLayerThickness:                      300,
 			InsetCount:                             2,
 			InfillOverlapPercent:                   50,
 			AdditionalInternalInfillOverlapPercent: 400,
 			InfillPercent:                          20,
 			InfillRotationDegree:                   45,
 			InfillZigZag:                           false,
+			InfillPattern:                          InfillPatternLinear,
+			TopBottomPattern:                       InfillPatternLinear,
 			NumberBottomLayers:                     3,
 			NumberTopLayers:                        4,
+			SpiralVase:                             false,
 			Support: SupportOptions{
 				Enabled:         false,
 				ThresholdAngle:  60,
@@ -422,6 +658,13 @@ func DefaultOptions() Options {
 				SkirtDistance: Millimeter(5),
 				BrimCount:     0,
 			},
+			Ironing: IroningOptions{
+				Enabled:     false,
+				Spacing:     Millimeter(0.1),
+				FlowPercent: 10,
+				Speed:       Millimeter(15),
+				Angle:       135,
+			},
 		},
 		Filament: FilamentOptions{
 			FilamentDiameter:             Millimeter(1.75).ToMicrometer(),
@@ -461,11 +704,18 @@ func DefaultOptions() Options {
 					"M84 ;steppers off",
 				}),
 		},
+		Transform: TransformOptions{
+			Scale: 1,
+		},
+		Arrange: ArrangeOptions{
+			Distance: 5,
+		},
 		GoSlice: GoSliceOptions{
 			PrintVersion:   false,
 			InputFilePath:  "",
 			OutputFilePath: "",
 			Logger:         log.New(os.Stdout, "", 0),
+			Concurrency:    0,
 		},
 	}
 }
@@ -476,18 +726,20 @@ func ParseFlags() Options {
 	options := DefaultOptions()
 
 	flag.Usage = func() {
-		_, _ = fmt.Fprintf(os.Stderr, "Usage of goslice: goslice STL_FILE [flags]\n")
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of goslice: goslice MODEL_FILE... [flags]\n")
 		flag.PrintDefaults()
 	}
 
 	// GoSlice options
 	flag.BoolVarP(&options.GoSlice.PrintVersion, "version", "v", false, "Print the GoSlice version.")
 	flag.StringVarP(&options.GoSlice.OutputFilePath, "output", "o", options.GoSlice.OutputFilePath, "File path for the output gcode file. Default is the inout file path with .gcode as file ending.")
+	flag.IntVar(&options.GoSlice.Concurrency, "concurrency", options.GoSlice.Concurrency, "The maximum amount of goroutines used to process the layers. 0 uses one per cpu.")
 
 	// Slicing options
 	flag.Var(&options.Slicing.MeldDistance, "meld-distance", "The distance which two points have to be within to count them as one point.")
 	flag.Var(&options.Slicing.JoinPolygonSnapDistance, "join-polygon-snap-distance", "The distance used to check if two open polygons can be snapped together to one bigger polygon. Checked by the start and endpoints of the polygons.")
 	flag.Var(&options.Slicing.FinishPolygonSnapDistance, "finish-polygon-snap-distance", "The max distance between start end endpoint of a polygon used to check if a open polygon can be closed.")
+	flag.BoolVar(&options.Slicing.RepairMesh, "repair-mesh", options.Slicing.RepairMesh, "Remove degenerate faces, fix flipped faces and fill holes of the model.")
 
 	// print options
 	flag.Var(&options.Print.IntialLayerSpeed, "initial-layer-speed", "The speed only for the first layer in mm per second.")
@@ -496,14 +748,20 @@ func ParseFlags() Options {
 	flag.Var(&options.Print.MoveSpeed, "move-speed", "The speed for all non printing moves.")
 	flag.Var(&options.Print.InitialLayerThickness, "initial-layer-thickness", "The layer thickness for the first layer.")
 	flag.Var(&options.Print.LayerThickness, "layer-thickness", "The thickness for all but the first layer.")
+	flag.BoolVar(&options.Print.AdaptiveLayers, "adaptive-layers", options.Print.AdaptiveLayers, "Use thin layers on flat slopes and thick layers on steep walls instead of the layer-thickness.")
+	flag.Var(&options.Print.MinLayerThickness, "min-layer-thickness", "The thickness of the thinnest adaptive layers.")
+	flag.Var(&options.Print.MaxLayerThickness, "max-layer-thickness", "The thickness of the thickest adaptive layers.")
 	flag.IntVar(&options.Print.InsetCount, "inset-count", options.Print.InsetCount, "The number of perimeters.")
 	flag.IntVar(&options.Print.InfillOverlapPercent, "infill-overlap-percent", options.Print.InfillOverlapPercent, "The percentage of overlap into the perimeters.")
 	flag.IntVar(&options.Print.AdditionalInternalInfillOverlapPercent, "additional-internal-infill-overlap-percent", options.Print.AdditionalInternalInfillOverlapPercent, "The percentage used to make the internal infill (infill not blocked by the perimeters) even bigger so that it grows a bit into the model.")
 	flag.IntVar(&options.Print.InfillPercent, "infill-percent", options.Print.InfillPercent, "The amount of infill which should be generated.")
 	flag.IntVar(&options.Print.InfillRotationDegree, "infill-rotation-degree", options.Print.InfillRotationDegree, "The rotation used for the infill.")
 	flag.BoolVar(&options.Print.InfillZigZag, "infill-zig-zag", options.Print.InfillZigZag, "Sets if the infill should use connected lines in zig zag form.")
+	flag.Var(&options.Print.InfillPattern, "infill-pattern", fmt.Sprintf("The pattern used for the internal infill. One of %v.", InfillPatterns))
+	flag.Var(topBottomPattern{&options.Print.TopBottomPattern}, "top-bottom-pattern", fmt.Sprintf("The pattern used for the top and bottom layers. One of %v.", TopBottomPatterns))
 	flag.IntVar(&options.Print.NumberBottomLayers, "number-bottom-layers", options.Print.NumberBottomLayers, "The amount of layers the bottom layers should grow into the model.")
 	flag.IntVar(&options.Print.NumberTopLayers, "number-top-layers", options.Print.NumberTopLayers, "The amount of layers the bottom layers should grow into the model.")
+	flag.BoolVar(&options.Print.SpiralVase, "spiral-vase", options.Print.SpiralVase, "Print everything above the bottom layers as one continuous spiral of the outer perimeter.")
 
 	// support options
 	flag.BoolVar(&options.Print.Support.Enabled, "support-enabled", options.Print.Support.Enabled, "Enables the generation of support structures.")
@@ -518,6 +776,13 @@ func ParseFlags() Options {
 	flag.Var(&options.Print.BrimSkirt.SkirtDistance, "skirt-distance", "The distance between the model (or the most outer brim lines) and the most inner skirt line.")
 	flag.IntVar(&options.Print.BrimSkirt.BrimCount, "brim-count", options.Print.BrimSkirt.BrimCount, "The amount of brim lines around the parts of the initial layer.")
 
+	// ironing options
+	flag.BoolVar(&options.Print.Ironing.Enabled, "ironing-enabled", options.Print.Ironing.Enabled, "Enables ironing of the topmost surfaces.")
+	flag.Var(&options.Print.Ironing.Spacing, "ironing-spacing", "The distance between the ironing lines.")
+	flag.IntVar(&options.Print.Ironing.FlowPercent, "ironing-flow-percent", options.Print.Ironing.FlowPercent, "The flow used for ironing in percent of the normal flow.")
+	flag.Var(&options.Print.Ironing.Speed, "ironing-speed", "The speed used for ironing in mm/s.")
+	flag.IntVar(&options.Print.Ironing.Angle, "ironing-angle", options.Print.Ironing.Angle, "The rotation of the ironing lines.")
+
 	// filament options
 	flag.Var(&options.Filament.FilamentDiameter, "filament-diameter", "The filament diameter used by the printer.")
 	flag.IntVar(&options.Filament.InitialBedTemperature, "initial-bed-temperature", options.Filament.InitialBedTemperature, "The temperature for the heated bed for the first layers.")
@@ -528,9 +793,27 @@ func ParseFlags() Options {
 	flag.Var(&options.Filament.RetractionSpeed, "retraction-speed", "The speed used for retraction in mm/s.")
 	flag.Var(&options.Filament.RetractionLength, "retraction-length", "The amount to retract in millimeter.")
 	flag.Var(&options.Filament.RetractionZHop, "retraction-z-hop", "The amount to lift head when retracting in millimeter.")
+	flag.Var(&options.Overrides, "height-override", "Change options between two heights in mm, e.g. --height-override 10-15:infill-percent=50,layer-speed=30. Supported are infill-percent, layer-speed, fan-speed and hot-end-temperature. Can be used several times.")
 	flag.Var(&options.Filament.FanSpeed, "fan-speed", "Comma separated layer/primary-fan-speed. eg. --fan-speed 3=20,10=40 indicates at layer 3 set fan to 20 and at layer 10 set fan to 40. Fan speed can range from 0-255.")
 	flag.IntVar(&options.Filament.ExtrusionMultiplier, "extrusion-multiplier", options.Filament.ExtrusionMultiplier, "The multiplier in % used to change the amount of filament being extruded. Can be used to mitigate under/over extrusion.")
 
+	// transform options
+	flag.Float64Var(&options.Transform.Scale, "scale", options.Transform.Scale, "The factor to scale the model with (1 = 100%).")
+	flag.Float64Var(&options.Transform.RotateX, "rotate-x", options.Transform.RotateX, "The rotation around the x axis in degree.")
+	flag.Float64Var(&options.Transform.RotateY, "rotate-y", options.Transform.RotateY, "The rotation around the y axis in degree.")
+	flag.Float64Var(&options.Transform.RotateZ, "rotate-z", options.Transform.RotateZ, "The rotation around the z axis in degree.")
+	flag.BoolVar(&options.Transform.MirrorX, "mirror-x", options.Transform.MirrorX, "Mirror the model by negating all x coordinates.")
+	flag.BoolVar(&options.Transform.MirrorY, "mirror-y", options.Transform.MirrorY, "Mirror the model by negating all y coordinates.")
+	flag.BoolVar(&options.Transform.MirrorZ, "mirror-z", options.Transform.MirrorZ, "Mirror the model by negating all z coordinates.")
+	flag.BoolVar(&options.Transform.LayFlat, "lay-flat", options.Transform.LayFlat, "Rotate the model automatically to get the most bed contact and the least overhangs.")
+	flag.Var(&options.Transform.TranslateX, "translate-x", "The distance in x direction to move the model away from the center.")
+	flag.Var(&options.Transform.TranslateY, "translate-y", "The distance in y direction to move the model away from the center.")
+
+	// arrange options
+	flag.Var(&options.Arrange.Distance, "arrange-distance", "The min distance between two models (without their brim).")
+	flag.BoolVar(&options.Arrange.SplitShells, "split-shells", options.Arrange.SplitShells, "Place the disconnected shells of each model separately.")
+	instances := flag.IntSlice("instances", nil, "Comma separated amount of copies for each model file, in the same order as the files. Missing values default to 1.")
+
 	// printer options
 	flag.Var(&options.Printer.ExtrusionWidth, "extrusion-width", "The diameter of your nozzle.")
 	center := microVec3{
@@ -539,6 +822,10 @@ func ParseFlags() Options {
 		options.Printer.Center.Z(),
 	}
 	flag.Var(&center, "center", "The point where the model is finally placed.")
+	flag.Var(&options.Printer.BedWidth, "bed-width", "The size of the bed in x direction. 0 disables the check if the print fits onto the bed.")
+	flag.Var(&options.Printer.BedDepth, "bed-depth", "The size of the bed in y direction. 0 disables the check if the print fits onto the bed.")
+	flag.Var(&options.Printer.BedHeight, "bed-height", "The max height which can be printed. 0 disables the check of the height.")
+	flag.BoolVar(&options.Printer.CircularBed, "circular-bed", options.Printer.CircularBed, "Use a round bed with the bed-width as diameter.")
 	flag.BoolVar(&options.Printer.ForceSafeStartStopGCode, "force-safe-gcode", options.Printer.ForceSafeStartStopGCode, "Enforce temp settings in start and end gcode hunks.")
 	flag.BoolVar(&options.Printer.HasHeatedBed, "has-heated-bed", options.Printer.HasHeatedBed, "Should the bed be heated?")
 	flag.Var(&options.Printer.StartGCode, "start-gcode", "Intructions to use for starting gcode hunk.")
@@ -553,5 +840,24 @@ func ParseFlags() Options {
 		options.GoSlice.InputFilePath = flag.Args()[0]
 	}
 
+	// Use all args together with their instance count as input files.
+	// If there are more instance counts than files, the additional files have an empty path.
+	fileCount := flag.NArg()
+	if len(*instances) > fileCount {
+		fileCount = len(*instances)
+	}
+	if fileCount > 1 || len(*instances) > 0 {
+		options.GoSlice.InputFiles = make([]InputFile, fileCount)
+		for i := range options.GoSlice.InputFiles {
+			options.GoSlice.InputFiles[i].Instances = 1
+			if i < flag.NArg() {
+				options.GoSlice.InputFiles[i].Path = flag.Args()[i]
+			}
+			if i < len(*instances) {
+				options.GoSlice.InputFiles[i].Instances = (*instances)[i]
+			}
+		}
+	}
+
 	return options
 }
@@ -157,3 +157,15 @@ func TestSetFanSpeedString(t *testing.T) {
 		}
 	}
 }
+
+func TestSetInfillPattern(t *testing.T) {
+	for _, pattern := range data.InfillPatterns {
+		var p data.InfillPattern
+		test.Ok(t, p.Set(string(pattern)))
+		test.Equals(t, pattern, p)
+	}
+
+	p := data.InfillPatternLinear
+	test.Assert(t, p.Set("unknown") != nil, "unknown patterns should not be accepted")
+	test.Equals(t, data.InfillPatternLinear, p)
+}
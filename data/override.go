@@ -0,0 +1,189 @@
+// This file provides options which only apply between specific heights.
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HeightOverride changes some options for all layers between two heights.
+// Only the options which are not nil are changed.
+type HeightOverride struct {
+	// From is the height where the override starts (inclusive).
+	From Millimeter
+
+	// To is the height where the override ends (exclusive).
+	To Millimeter
+
+	// InfillPercent replaces PrintOptions.InfillPercent.
+	InfillPercent *int
+
+	// LayerSpeed replaces PrintOptions.LayerSpeed.
+	LayerSpeed *Millimeter
+
+	// FanSpeed is the fan speed (0-255) which is used instead of the FilamentOptions.FanSpeed.
+	FanSpeed *int
+
+	// HotEndTemperature replaces FilamentOptions.HotEndTemperature.
+	HotEndTemperature *int
+}
+
+// contains checks if the height of a layer is inside of the range of the override.
+func (h HeightOverride) contains(z Micrometer) bool {
+	return z >= h.From.ToMicrometer() && z < h.To.ToMicrometer()
+}
+
+func (h HeightOverride) String() string {
+	var values []string
+	if h.InfillPercent != nil {
+		values = append(values, fmt.Sprintf("infill-percent=%d", *h.InfillPercent))
+	}
+	if h.LayerSpeed != nil {
+		values = append(values, fmt.Sprintf("layer-speed=%v", *h.LayerSpeed))
+	}
+	if h.FanSpeed != nil {
+		values = append(values, fmt.Sprintf("fan-speed=%d", *h.FanSpeed))
+	}
+	if h.HotEndTemperature != nil {
+		values = append(values, fmt.Sprintf("hot-end-temperature=%d", *h.HotEndTemperature))
+	}
+	return fmt.Sprintf("%v-%v:%s", h.From, h.To, strings.Join(values, ","))
+}
+
+// HeightOverrides is a list of overrides.
+// If several overrides contain the same height, the later ones win.
+type HeightOverrides []HeightOverride
+
+func (h HeightOverrides) Type() string {
+	return "HeightOverride"
+}
+
+func (h HeightOverrides) String() string {
+	var s []string
+	for _, override := range h {
+		s = append(s, override.String())
+	}
+	return strings.Join(s, " ")
+}
+
+// Set adds an override in the format from-to:key=value,key=value
+// The heights are in mm. Supported keys are infill-percent, layer-speed, fan-speed and hot-end-temperature.
+// It can be used several times to add several overrides.
+func (h *HeightOverrides) Set(s string) error {
+	errMessage := "a height override needs to be in format from-to:key=value,key=value e.g. 10-15:infill-percent=50"
+
+	rangeAndValues := strings.SplitN(s, ":", 2)
+	if len(rangeAndValues) != 2 {
+		return errors.New(errMessage)
+	}
+
+	heights := strings.Split(rangeAndValues[0], "-")
+	if len(heights) != 2 {
+		return errors.New(errMessage)
+	}
+
+	var override HeightOverride
+	if err := override.From.Set(heights[0]); err != nil {
+		return errors.New(errMessage)
+	}
+	if err := override.To.Set(heights[1]); err != nil {
+		return errors.New(errMessage)
+	}
+	if override.From < 0 || override.To <= override.From {
+		return errors.New("the end of a height override has to be above its start")
+	}
+
+	for _, kvp := range strings.Split(rangeAndValues[1], ",") {
+		kv := strings.Split(kvp, "=")
+		if len(kv) != 2 {
+			return errors.New(errMessage)
+		}
+
+		if kv[0] == "layer-speed" {
+			var speed Millimeter
+			if err := speed.Set(kv[1]); err != nil || speed <= 0 {
+				return fmt.Errorf("invalid layer-speed %q for a height override", kv[1])
+			}
+			override.LayerSpeed = &speed
+			continue
+		}
+
+		value, err := strconv.Atoi(kv[1])
+		if err != nil {
+			return fmt.Errorf("invalid %s %q for a height override", kv[0], kv[1])
+		}
+
+		switch kv[0] {
+		case "infill-percent":
+			if value < 0 || value > 100 {
+				return errors.New("the infill-percent of a height override has to be between 0 and 100")
+			}
+			override.InfillPercent = &value
+		case "fan-speed":
+			if value < 0 || value > 255 {
+				return errors.New("the fan-speed of a height override has to be between 0 and 255")
+			}
+			override.FanSpeed = &value
+		case "hot-end-temperature":
+			override.HotEndTemperature = &value
+		default:
+			return fmt.Errorf("unknown option %q for a height override", kv[0])
+		}
+	}
+
+	*h = append(*h, override)
+	return nil
+}
+
+// At returns the combination of all overrides which contain the given height.
+// The options which are not changed at this height are nil.
+func (h HeightOverrides) At(z Micrometer) HeightOverride {
+	var result HeightOverride
+	for _, override := range h {
+		if !override.contains(z) {
+			continue
+		}
+
+		if override.InfillPercent != nil {
+			result.InfillPercent = override.InfillPercent
+		}
+		if override.LayerSpeed != nil {
+			result.LayerSpeed = override.LayerSpeed
+		}
+		if override.FanSpeed != nil {
+			result.FanSpeed = override.FanSpeed
+		}
+		if override.HotEndTemperature != nil {
+			result.HotEndTemperature = override.HotEndTemperature
+		}
+	}
+
+	return result
+}
+
+// ForHeight returns the options for a layer at the given height with all Overrides applied.
+// If no override contains the height, the options itself are returned.
+// The returned options must not be changed.
+func (o *Options) ForHeight(z Micrometer) *Options {
+	// The fan speed is applied by the renderers as the FanSpeedOptions only support layer numbers.
+	override := o.Overrides.At(z)
+	if override.InfillPercent == nil && override.LayerSpeed == nil && override.HotEndTemperature == nil {
+		return o
+	}
+
+	result := *o
+	if override.InfillPercent != nil {
+		result.Print.InfillPercent = *override.InfillPercent
+	}
+	if override.LayerSpeed != nil {
+		result.Print.LayerSpeed = *override.LayerSpeed
+	}
+	if override.HotEndTemperature != nil {
+		result.Filament.HotEndTemperature = *override.HotEndTemperature
+	}
+
+	return &result
+}
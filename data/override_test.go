@@ -0,0 +1,82 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/util/test"
+)
+
+func TestSetHeightOverrides(t *testing.T) {
+	var tests = map[string]struct {
+		values   []string
+		expected string
+		err      bool
+	}{
+		"one override": {
+			values:   []string{"10-15:infill-percent=50"},
+			expected: "10.000-15.000:infill-percent=50",
+		},
+		"several overrides": {
+			values:   []string{"10-15:infill-percent=50,layer-speed=30", "0-2.5:fan-speed=0,hot-end-temperature=210"},
+			expected: "10.000-15.000:infill-percent=50,layer-speed=30.000 0.000-2.500:fan-speed=0,hot-end-temperature=210",
+		},
+		"missing range": {
+			values: []string{"infill-percent=50"},
+			err:    true,
+		},
+		"wrong range": {
+			values: []string{"15-10:infill-percent=50"},
+			err:    true,
+		},
+		"unknown option": {
+			values: []string{"10-15:inset-count=2"},
+			err:    true,
+		},
+		"invalid value": {
+			values: []string{"10-15:fan-speed=300"},
+			err:    true,
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log("test case:", name)
+
+		var overrides data.HeightOverrides
+		var err error
+		for _, value := range testCase.values {
+			if err = overrides.Set(value); err != nil {
+				break
+			}
+		}
+
+		test.Equals(t, testCase.err, err != nil)
+		if !testCase.err {
+			test.Equals(t, testCase.expected, overrides.String())
+		}
+	}
+}
+
+func TestForHeight(t *testing.T) {
+	options := data.DefaultOptions()
+	test.Ok(t, options.Overrides.Set("10-15:infill-percent=50,layer-speed=30"))
+	test.Ok(t, options.Overrides.Set("12-20:infill-percent=80"))
+
+	test.Assert(t, options.ForHeight(9800) == &options, "the options should not be copied outside of the overrides")
+	test.Assert(t, options.ForHeight(20000) == &options, "the end of an override should be exclusive")
+
+	layerOptions := options.ForHeight(10000)
+	test.Equals(t, 50, layerOptions.Print.InfillPercent)
+	test.Equals(t, data.Millimeter(30), layerOptions.Print.LayerSpeed)
+
+	layerOptions = options.ForHeight(12000)
+	test.Equals(t, 80, layerOptions.Print.InfillPercent)
+	test.Equals(t, data.Millimeter(30), layerOptions.Print.LayerSpeed)
+
+	layerOptions = options.ForHeight(15000)
+	test.Equals(t, 80, layerOptions.Print.InfillPercent)
+	test.Equals(t, data.DefaultOptions().Print.LayerSpeed, layerOptions.Print.LayerSpeed)
+
+	// The original options are not changed.
+	test.Equals(t, data.DefaultOptions().Print.InfillPercent, options.Print.InfillPercent)
+}
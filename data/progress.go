@@ -0,0 +1,52 @@
+// This file provides types to report the progress of the slicing process.
+
+package data
+
+import "time"
+
+// Stage is a step of the slicing process.
+type Stage string
+
+const (
+	StageRead      Stage = "read"
+	StageTransform Stage = "transform"
+	StageArrange   Stage = "arrange"
+	StageOptimize  Stage = "optimize"
+	StageSlice     Stage = "slice"
+	StageModify    Stage = "modify"
+	StageGenerate  Stage = "generate"
+	StageDone      Stage = "done"
+)
+
+// Progress describes the current state of the slicing process.
+type Progress struct {
+	// Stage is the currently running step.
+	Stage Stage
+
+	// Name is the name of the currently running handler, e.g. the name of the modifier.
+	// It may be empty.
+	Name string
+
+	// Layer is the amount of layers which are already finished in the current stage.
+	// It is always between 0 and LayerCount.
+	Layer int
+
+	// LayerCount is the amount of layers to process in the current stage.
+	// It is 0 as long as the layers are not known yet.
+	LayerCount int
+
+	// Elapsed is the time since the start of the slicing process.
+	Elapsed time.Duration
+}
+
+// ProgressFunc is called each time the slicing process makes progress.
+// It is called synchronously, so it should return fast.
+// The built in handlers never call it concurrently, even if they process the layers concurrently.
+type ProgressFunc func(progress Progress)
+
+// Report calls the ProgressFunc if it is not nil.
+func (f ProgressFunc) Report(progress Progress) {
+	if f != nil {
+		f(progress)
+	}
+}
@@ -0,0 +1,36 @@
+// This file provides the report of the model repair done by the optimizer.
+
+package data
+
+import "fmt"
+
+// RepairReport describes which errors of the model were found and fixed by the optimizer.
+type RepairReport struct {
+	// DegenerateFaces is the amount of removed faces without any area.
+	DegenerateFaces int `json:"degenerateFaces"`
+
+	// DuplicateFaces is the amount of removed faces which use the same points as another face.
+	DuplicateFaces int `json:"duplicateFaces"`
+
+	// SplitFaces is the amount of faces which were split at a point on their edge (a T-junction)
+	// to close the gaps left by removed degenerate faces. Each split adds one face.
+	SplitFaces int `json:"splitFaces"`
+
+	// FlippedFaces is the amount of faces which were turned to match the orientation of their neighbours.
+	FlippedFaces int `json:"flippedFaces"`
+
+	// FilledHoles is the amount of holes which were closed.
+	FilledHoles int `json:"filledHoles"`
+
+	// AddedFaces is the amount of faces added to close the holes.
+	AddedFaces int `json:"addedFaces"`
+
+	// OpenEdges is the amount of edges which still have no touching face after the repair.
+	// If it is not 0, the model still has errors.
+	OpenEdges int `json:"openEdges"`
+}
+
+func (r RepairReport) String() string {
+	return fmt.Sprintf("removed %d degenerate and %d duplicate faces, split %d faces, flipped %d faces, filled %d holes with %d faces, %d open edges left",
+		r.DegenerateFaces, r.DuplicateFaces, r.SplitFaces, r.FlippedFaces, r.FilledHoles, r.AddedFaces, r.OpenEdges)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 
 	"github.com/aligator/goslice/clip"
@@ -41,10 +42,18 @@ func NewGCodeBuilder(options *data.Options) *Builder {
 	return g
 }
 
+// String returns the GCode which was added since the last Flush.
 func (g *Builder) String() string {
 	return g.buf.String()
 }
 
+// Flush writes the GCode which was added since the last Flush to w.
+// The written GCode is removed from the Builder afterwards.
+func (g *Builder) Flush(w io.Writer) error {
+	_, err := g.buf.WriteTo(w)
+	return err
+}
+
 func (g *Builder) SetExtrusion(layerThickness, lineWidth data.Micrometer) {
 	filamentArea := math.Pi * (g.filamentDiameter.ToMillimeter() / 2.0) * (g.filamentDiameter.ToMillimeter() / 2.0)
 	g.extrusionPerMM = (layerThickness.ToMillimeter() * lineWidth.ToMillimeter() / filamentArea) * (data.Millimeter(g.extrusionMultiplier) / 100)
@@ -156,57 +165,10 @@ func (g *Builder) AddPolygon(currentLayer data.PartitionedLayer, polygon data.Pa
 
 	for i, p := range polygon {
 		if i == 0 {
-			// for the move to the polygon: detect move through perimeters and add retraction if needed
-			// TODO: this is very ineffective, as it has to clip for every first move of every polygon with the whole layer...
-			move := data.Path{
-				g.currentPosition.PointXY(),
-				polygon[0],
-			}
-
-			isCrossing := false
-			if currentLayer != nil && g.retractionSpeed != 0 && g.retractionAmount != 0 {
-				c := clip.NewClipper()
-				var ok bool
-				isCrossing, ok = c.IsCrossingPerimeter(currentLayer.LayerParts(), move)
-
-				if !ok {
-					return errors.New("could not calculate the difference between the current layer and the non-extrusion-move")
-				}
+			err := g.moveTo(currentLayer, p, z)
+			if err != nil {
+				return err
 			}
-
-			zMove := z
-
-			if isCrossing {
-				g.AddMoveSpeed(g.currentPosition, -g.retractionAmount, g.retractionSpeed)
-
-				if g.zHopOnRetract > 0 {
-					zMove = z + g.zHopOnRetract.ToMicrometer()
-
-					g.AddMove(data.NewMicroVec3(
-						g.currentPosition.X(),
-						g.currentPosition.Y(),
-						zMove,
-					), 0.0)
-				}
-			}
-
-			g.AddMove(data.NewMicroVec3(
-				polygon[i].X(),
-				polygon[i].Y(),
-				zMove), 0.0)
-
-			if isCrossing {
-				if g.zHopOnRetract > 0 {
-					g.AddMove(data.NewMicroVec3(
-						polygon[i].X(),
-						polygon[i].Y(),
-						z,
-					), 0.0)
-				}
-
-				g.AddMoveSpeed(g.currentPosition, g.retractionAmount, g.retractionSpeed)
-			}
-
 			continue
 		}
 
@@ -237,3 +199,101 @@ func (g *Builder) AddPolygon(currentLayer data.PartitionedLayer, polygon data.Pa
 
 	return nil
 }
+
+// AddSpiral adds a closed polygon which rises continuously from fromZ at its first point
+// to toZ when it reaches the first point again.
+func (g *Builder) AddSpiral(currentLayer data.PartitionedLayer, polygon data.Path, fromZ, toZ data.Micrometer) error {
+	if len(polygon) == 0 {
+		return nil
+	}
+
+	// smooth the polygon
+	polygon = data.DouglasPeucker(polygon, -1)
+
+	// close the polygon so that the last move also rises
+	polygon = append(polygon, polygon[0])
+
+	var length float64
+	for i := 1; i < len(polygon); i++ {
+		length += float64(polygon[i].Sub(polygon[i-1]).Size())
+	}
+
+	err := g.moveTo(currentLayer, polygon[0], fromZ)
+	if err != nil {
+		return err
+	}
+
+	var done float64
+	for i := 1; i < len(polygon); i++ {
+		segment := polygon[i].Sub(polygon[i-1])
+		done += float64(segment.Size())
+
+		z := toZ
+		if length > 0 && i < len(polygon)-1 {
+			z = fromZ + data.Micrometer(float64(toZ-fromZ)*done/length)
+		}
+
+		g.AddMove(
+			data.NewMicroVec3(polygon[i].X(), polygon[i].Y(), z),
+			segment.SizeMM()*g.extrusionPerMM,
+		)
+	}
+
+	return nil
+}
+
+// moveTo moves to the given point without extruding.
+// It detects moves through perimeters and adds a retraction if needed.
+func (g *Builder) moveTo(currentLayer data.PartitionedLayer, p data.MicroPoint, z data.Micrometer) error {
+	// TODO: this is very ineffective, as it has to clip for every first move of every polygon with the whole layer...
+	move := data.Path{
+		g.currentPosition.PointXY(),
+		p,
+	}
+
+	isCrossing := false
+	if currentLayer != nil && g.retractionSpeed != 0 && g.retractionAmount != 0 {
+		c := clip.NewClipper()
+		var ok bool
+		isCrossing, ok = c.IsCrossingPerimeter(currentLayer.LayerParts(), move)
+
+		if !ok {
+			return errors.New("could not calculate the difference between the current layer and the non-extrusion-move")
+		}
+	}
+
+	zMove := z
+
+	if isCrossing {
+		g.AddMoveSpeed(g.currentPosition, -g.retractionAmount, g.retractionSpeed)
+
+		if g.zHopOnRetract > 0 {
+			zMove = z + g.zHopOnRetract.ToMicrometer()
+
+			g.AddMove(data.NewMicroVec3(
+				g.currentPosition.X(),
+				g.currentPosition.Y(),
+				zMove,
+			), 0.0)
+		}
+	}
+
+	g.AddMove(data.NewMicroVec3(
+		p.X(),
+		p.Y(),
+		zMove), 0.0)
+
+	if isCrossing {
+		if g.zHopOnRetract > 0 {
+			g.AddMove(data.NewMicroVec3(
+				p.X(),
+				p.Y(),
+				z,
+			), 0.0)
+		}
+
+		g.AddMoveSpeed(g.currentPosition, g.retractionAmount, g.retractionSpeed)
+	}
+
+	return nil
+}
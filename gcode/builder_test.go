@@ -1,6 +1,7 @@
 package gcode_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/aligator/goslice/data"
@@ -200,6 +201,24 @@ G0 Y0.00
 				"G1 Y10.00 E0.3326\n",
 		},
 
+		"add spiral": {
+			exec: func(b *gcode.Builder) {
+				b.SetExtrusion(200, 400)
+				err := b.AddSpiral(nil, data.Path{
+					data.NewMicroPoint(0, 0),
+					data.NewMicroPoint(10000, 0),
+					data.NewMicroPoint(10000, 10000),
+					data.NewMicroPoint(0, 10000),
+				}, 200, 400)
+				test.Ok(t, err)
+			},
+			expected: "G0 X0.00 Y0.00 Z0.20\n" +
+				"G1 X10.00 Z0.25 E0.3326\n" +
+				"G1 Y10.00 Z0.30 E0.6652\n" +
+				"G1 X0.00 Z0.35 E0.9978\n" +
+				"G1 Y0.00 Z0.40 E1.3304\n",
+		},
+
 		"set extrusion with over extrusion": {
 			options: &overExtrusionOptions,
 			exec: func(b *gcode.Builder) {
@@ -241,3 +260,17 @@ G0 Y0.00
 		test.Equals(t, testCase.expected, builder.String())
 	}
 }
+
+func TestGCodeBuilderFlush(t *testing.T) {
+	options := data.DefaultOptions()
+	builder := gcode.NewGCodeBuilder(&options)
+
+	var out bytes.Buffer
+	builder.AddCommand("G28")
+	test.Ok(t, builder.Flush(&out))
+	test.Equals(t, "", builder.String())
+
+	builder.AddComment("LAYER:%v", 1)
+	test.Ok(t, builder.Flush(&out))
+	test.Equals(t, "G28\n;LAYER:1\n", out.String())
+}
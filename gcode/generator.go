@@ -2,6 +2,9 @@
 package gcode
 
 import (
+	"context"
+	"io"
+
 	"github.com/aligator/goslice/data"
 	"github.com/aligator/goslice/handler"
 )
@@ -14,6 +17,7 @@ type Renderer interface {
 	Init(model data.OptimizedModel)
 
 	// Render is called for each layer and the provided Builder can be used to add gcode.
+	// The options are the options of this layer with the height overrides applied (see data.Options.ForHeight).
 	Render(b *Builder, layerNr int, maxLayer int, layer data.PartitionedLayer, z data.Micrometer, options *data.Options) error
 }
 
@@ -58,22 +62,37 @@ func (g *generator) init() {
 }
 
 // Generate generates the GCode by using the renderers added to the generator.
-// The final GCode is just returned as string.
-func (g *generator) Generate(layers []data.PartitionedLayer) (string, error) {
+// The GCode of each layer is written to w as soon as the layer is rendered.
+func (g *generator) Generate(ctx context.Context, layers []data.PartitionedLayer, w io.Writer) error {
 	g.init()
 
 	maxLayer := len(layers) - 1
 
 	for layerNr := range layers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		g.options.GoSlice.Logger.Printf("Render layer %d/%d\n", layerNr, maxLayer)
+		z := layers[layerNr].Z()
+		layerOptions := g.options.ForHeight(z)
 		for _, renderer := range g.renderers {
-			z := g.options.Print.InitialLayerThickness + data.Micrometer(layerNr)*g.options.Print.LayerThickness
-			err := renderer.Render(g.builder, layerNr, maxLayer, layers[layerNr], z, g.options)
+			err := renderer.Render(g.builder, layerNr, maxLayer, layers[layerNr], z, layerOptions)
 			if err != nil {
-				return "", err
+				return err
 			}
 		}
+
+		if err := g.builder.Flush(w); err != nil {
+			return err
+		}
+
+		g.options.GoSlice.Progress.Report(data.Progress{
+			Stage:      data.StageGenerate,
+			Layer:      layerNr + 1,
+			LayerCount: len(layers),
+		})
 	}
 
-	return g.builder.String(), nil
+	return nil
 }
@@ -1,6 +1,8 @@
 package gcode_test
 
 import (
+	"bytes"
+	"context"
 	"github.com/aligator/goslice/data"
 	"github.com/aligator/goslice/gcode"
 	"github.com/aligator/goslice/util/test"
@@ -31,14 +33,18 @@ func (f *fakeRenderer) Init(model data.OptimizedModel) {
 func (f *fakeRenderer) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.PartitionedLayer, z data.Micrometer, options *data.Options) error {
 	f.c.c["render"]++
 	test.Assert(f.t, maxLayer >= layerNr, "the number of layers should be more or equal than the current layer number")
-	b.AddCommand("number %v", layerNr)
+	b.AddCommand("number %v z %v", layerNr, z)
 	return nil
 }
 
 func TestGCodeGenerator(t *testing.T) {
 	rendererCounter := newCounter()
 
-	layers := make([]data.PartitionedLayer, 3)
+	layers := []data.PartitionedLayer{
+		data.NewPartitionedLayer(nil, 200, 200),
+		data.NewPartitionedLayer(nil, 300, 100),
+		data.NewPartitionedLayer(nil, 600, 300),
+	}
 
 	generator := gcode.NewGenerator(&data.Options{
 		GoSlice: data.GoSliceOptions{
@@ -46,13 +52,14 @@ func TestGCodeGenerator(t *testing.T) {
 		},
 	}, gcode.WithRenderer(&fakeRenderer{t: t, c: rendererCounter}))
 	generator.Init(nil)
-	result, err := generator.Generate(layers)
+	var result bytes.Buffer
+	err := generator.Generate(context.Background(), layers, &result)
 
 	test.Ok(t, err)
 
 	test.Assert(t, rendererCounter.c["init"] == 1, "init should have been called only one time")
 	test.Assert(t, rendererCounter.c["render"] == len(layers), "render should have been called %v times (one for each layer)", len(layers))
-	test.Equals(t, "number 0\n"+
-		"number 1\n"+
-		"number 2\n", result)
+	test.Equals(t, "number 0 z 200\n"+
+		"number 1 z 300\n"+
+		"number 2 z 600\n", result.String())
 }
@@ -12,9 +12,11 @@ import (
 // Infill is a renderer which can fill parts which are defined by a layer part attribute of a specific name.
 // The attribute has to be of type []data.LayerPart.
 type Infill struct {
-	// PatternSetup is called once on init and sets a specific pattern this infill renderer should use.
-	// Min and max define the dimension of the model (in X and Y direction)
-	PatternSetup func(min data.MicroPoint, max data.MicroPoint) clip.Pattern
+	// PatternSetup sets a specific pattern this infill renderer should use.
+	// Min and max define the dimension of the model (in X and Y direction).
+	// It is called on the first layer and again for each layer which has other options.Print because of height overrides.
+	// As height overrides only change the print options, the pattern must not depend on other options which may change.
+	PatternSetup func(min data.MicroPoint, max data.MicroPoint, options *data.Options) clip.Pattern
 
 	// AttrName is the name of the attribute containing the []data.LayerPart's to fill.
 	AttrName string
@@ -22,14 +24,28 @@ type Infill struct {
 	// Comments is a list of comments to be added before each infill.
 	Comments []string
 
-	pattern clip.Pattern
+	min, max data.MicroPoint
+	pattern  clip.Pattern
+
+	// patternPrint are the print options the pattern was set up with, nil if there is no pattern yet.
+	// The values are compared as the options of each layer inside of a height override are a new instance.
+	patternPrint *data.PrintOptions
 }
 
 func (i *Infill) Init(model data.OptimizedModel) {
-	i.pattern = i.PatternSetup(model.Min().PointXY(), model.Max().PointXY())
+	i.min = model.Min().PointXY()
+	i.max = model.Max().PointXY()
+	i.pattern = nil
+	i.patternPrint = nil
 }
 
 func (i *Infill) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.PartitionedLayer, z data.Micrometer, options *data.Options) error {
+	if i.patternPrint == nil || *i.patternPrint != options.Print {
+		i.pattern = i.PatternSetup(i.min, i.max, options)
+		printOptions := options.Print
+		i.patternPrint = &printOptions
+	}
+
 	if i.pattern == nil {
 		return nil
 	}
@@ -47,7 +63,7 @@ func (i *Infill) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.
 			b.AddComment(c)
 		}
 
-		infill, err := i.pattern.Fill(layerNr, part)
+		infill, err := i.pattern.Fill(layerNr, z, part)
 		if err != nil {
 			return err
 		}
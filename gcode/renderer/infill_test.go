@@ -0,0 +1,48 @@
+package renderer_test
+
+import (
+	"io/ioutil"
+	"log"
+	"testing"
+
+	"github.com/aligator/goslice/clip"
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/gcode"
+	"github.com/aligator/goslice/gcode/renderer"
+	"github.com/aligator/goslice/optimizer"
+	"github.com/aligator/goslice/util/test"
+)
+
+func TestInfillPatternSetup(t *testing.T) {
+	o := data.DefaultOptions()
+	o.GoSlice.Logger = log.New(ioutil.Discard, "", 0)
+	test.Ok(t, o.Overrides.Set("1-2:infill-percent=50,layer-speed=20"))
+
+	model, err := optimizer.NewOptimizer(&o).Optimize(data.NewBasicModel([]data.Face{
+		data.NewBasicFace([3]data.MicroVec3{
+			data.NewMicroVec3(0, 0, 0),
+			data.NewMicroVec3(10000, 0, 0),
+			data.NewMicroVec3(0, 10000, 10000),
+		}),
+	}))
+	test.Ok(t, err)
+
+	var percents []int
+	infill := &renderer.Infill{
+		PatternSetup: func(min data.MicroPoint, max data.MicroPoint, options *data.Options) clip.Pattern {
+			percents = append(percents, options.Print.InfillPercent)
+			return nil
+		},
+		AttrName: "infill",
+	}
+	infill.Init(model)
+
+	b := gcode.NewGCodeBuilder(&o)
+	for layerNr := 0; layerNr < 15; layerNr++ {
+		z := data.Micrometer(layerNr+1) * 200
+		test.Ok(t, infill.Render(b, layerNr, 14, data.NewPartitionedLayer(nil, z, 200), z, o.ForHeight(z)))
+	}
+
+	// The pattern is only set up again if the options of the layer change.
+	test.Equals(t, []int{o.Print.InfillPercent, 50, o.Print.InfillPercent}, percents)
+}
@@ -0,0 +1,63 @@
+// This file provides a renderer for ironing the top surfaces.
+
+package renderer
+
+import (
+	"github.com/aligator/goslice/clip"
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/gcode"
+	"github.com/aligator/goslice/modifier"
+)
+
+// Ironing is a renderer which moves over the areas of the attribute "ironing" a second time
+// using a dense linear pattern with very low flow to smooth the topmost surfaces.
+// It only renders if options.Print.Ironing is enabled.
+type Ironing struct {
+	min, max data.MicroPoint
+}
+
+func (i *Ironing) Init(model data.OptimizedModel) {
+	i.min = model.Min().PointXY()
+	i.max = model.Max().PointXY()
+}
+
+func (i *Ironing) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.PartitionedLayer, z data.Micrometer, options *data.Options) error {
+	if !options.Print.Ironing.Enabled {
+		return nil
+	}
+
+	parts, err := modifier.Ironing(layer)
+	if err != nil {
+		return err
+	}
+	if parts == nil {
+		return nil
+	}
+
+	pattern := clip.NewLinearPattern(options.Printer.ExtrusionWidth, options.Print.Ironing.Spacing.ToMicrometer(), i.min, i.max, options.Print.Ironing.Angle, false, true)
+
+	// Use only a small part of the normal flow to fill the gaps of the surface.
+	b.SetExtrusion(layer.Thickness()*data.Micrometer(options.Print.Ironing.FlowPercent)/100, options.Printer.ExtrusionWidth)
+	b.SetExtrudeSpeed(options.Print.Ironing.Speed)
+
+	for _, part := range parts {
+		b.AddComment("TYPE:IRONING")
+
+		paths, err := pattern.Fill(layerNr, z, part)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			err := b.AddPolygon(layer, path, z, true)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Restore the normal flow and speed for the following renderers.
+	b.SetExtrusion(layer.Thickness(), options.Printer.ExtrusionWidth)
+	b.SetExtrudeSpeed(options.Print.LayerSpeed)
+
+	return nil
+}
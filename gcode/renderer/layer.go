@@ -53,8 +53,6 @@ func (PreLayer) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.P
 
 		b.AddCommand("G92 E0 ; reset extrusion distance")
 
-		b.SetExtrusion(options.Print.InitialLayerThickness, options.Printer.ExtrusionWidth)
-
 		// set speeds
 		b.SetExtrudeSpeed(options.Print.LayerSpeed)
 		b.SetMoveSpeed(options.Print.MoveSpeed)
@@ -66,10 +64,11 @@ func (PreLayer) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.P
 
 		// force the InitialLayerSpeed for first layer
 		b.SetExtrudeSpeedOverride(options.Print.IntialLayerSpeed)
-	} else if layerNr == 1 {
-		b.SetExtrusion(options.Print.LayerThickness, options.Printer.ExtrusionWidth)
 	}
 
+	// Each layer may have a different thickness.
+	b.SetExtrusion(layer.Thickness(), options.Printer.ExtrusionWidth)
+
 	b.AddComment("LAYER:%v", layerNr)
 
 	if layerNr > 0 {
@@ -77,7 +76,23 @@ func (PreLayer) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.P
 		b.SetExtrudeSpeed(options.Print.LayerSpeed)
 	}
 
-	if fanSpeed, ok := options.Filament.FanSpeed.LayerToSpeedLUT[layerNr]; ok {
+	// Height overrides change the fan speed and temperature when their range is entered or left.
+	override := options.Overrides.At(z)
+	var previous data.HeightOverride
+	if layerNr > 0 {
+		previous = options.Overrides.At(z - layer.Thickness())
+	}
+
+	fanSpeed, setFanSpeed := options.Filament.FanSpeed.LayerToSpeedLUT[layerNr]
+	if override.FanSpeed != nil {
+		fanSpeed = *override.FanSpeed
+		setFanSpeed = previous.FanSpeed == nil || *previous.FanSpeed != fanSpeed
+	} else if previous.FanSpeed != nil {
+		fanSpeed = options.Filament.FanSpeed.SpeedAt(layerNr)
+		setFanSpeed = true
+	}
+
+	if setFanSpeed {
 		if fanSpeed == 0 {
 			b.AddCommand("M107 ; disable fan")
 		} else {
@@ -91,6 +106,17 @@ func (PreLayer) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.P
 		b.AddComment("SET_TEMP")
 		b.AddCommand("M140 S%d", options.Filament.BedTemperature)
 		b.AddCommand("M104 S%d", options.Filament.HotEndTemperature)
+	} else if override.HotEndTemperature != nil {
+		if previous.HotEndTemperature == nil || *previous.HotEndTemperature != *override.HotEndTemperature {
+			b.AddCommand("M104 S%d", *override.HotEndTemperature)
+		}
+	} else if previous.HotEndTemperature != nil {
+		// Restore the temperature which was used before the override.
+		temperature := options.Filament.HotEndTemperature
+		if layerNr < options.Filament.InitialTemperatureLayerCount {
+			temperature = options.Filament.InitialHotEndTemperature
+		}
+		b.AddCommand("M104 S%d", temperature)
 	}
 
 	return nil
@@ -105,7 +131,6 @@ func (PostLayer) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.
 	// ending gcode
 	if layerNr == maxLayer {
 		b.AddComment("END_GCODE")
-		b.SetExtrusion(options.Print.LayerThickness, options.Printer.ExtrusionWidth)
 
 		if options.Printer.ForceSafeStartStopGCode {
 			// disable heaters
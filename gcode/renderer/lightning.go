@@ -0,0 +1,38 @@
+// This file provides a renderer for the lightning infill.
+
+package renderer
+
+import (
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/gcode"
+	"github.com/aligator/goslice/modifier"
+)
+
+// Lightning is a renderer which prints the lines of the attribute "lightning".
+// In contrast to the Infill renderer the lines are already generated by the modifier
+// as they depend on the layers above.
+type Lightning struct{}
+
+func (Lightning) Init(model data.OptimizedModel) {}
+
+func (Lightning) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.PartitionedLayer, z data.Micrometer, options *data.Options) error {
+	lines, err := modifier.LightningInfill(layer)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	b.AddComment("TYPE:FILL")
+	b.AddComment("INTERNAL-FILL")
+
+	for _, line := range lines {
+		err := b.AddPolygon(layer, line, z, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
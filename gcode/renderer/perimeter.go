@@ -14,6 +14,11 @@ type Perimeter struct{}
 func (p Perimeter) Init(model data.OptimizedModel) {}
 
 func (p Perimeter) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.PartitionedLayer, z data.Micrometer, options *data.Options) error {
+	// The Spiral renderer prints these layers.
+	if isSpiralLayer(layerNr, options) {
+		return nil
+	}
+
 	perimeters, err := modifier.Perimeters(layer)
 	if err != nil {
 		return err
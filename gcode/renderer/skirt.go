@@ -41,9 +41,7 @@ func (Skirt) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.Part
 			return nil
 		}
 
-		// Skirt distance + (1/2 extrusion with of the model side + 1/2 extrusion width of the most inner brim line) + the brim width
-		// is the distance between the perimeter (or brim) and skirt.
-		distance := options.Print.BrimSkirt.SkirtDistance.ToMicrometer() + (options.Printer.ExtrusionWidth * data.Micrometer(options.Print.BrimSkirt.BrimCount)) + options.Printer.ExtrusionWidth
+		distance := modifier.SkirtDistance(options)
 
 		// Draw the skirt.
 		c := clip.NewClipper()
@@ -0,0 +1,71 @@
+// This file provides a renderer for the spiral vase mode.
+
+package renderer
+
+import (
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/gcode"
+	"github.com/aligator/goslice/modifier"
+)
+
+// Spiral is a renderer which prints the outer perimeter of all layers above the bottom layers
+// as one continuous spiral if options.Print.SpiralVase is enabled.
+// Each layer rises from the top of the layer below to its own z, so there is no seam.
+// The Perimeter renderer skips these layers.
+type Spiral struct {
+	// last is the end of the spiral on the previous layer.
+	last data.MicroPoint
+}
+
+func (s *Spiral) Init(model data.OptimizedModel) {
+	s.last = nil
+}
+
+// isSpiralLayer returns true if the layer is printed as spiral.
+func isSpiralLayer(layerNr int, options *data.Options) bool {
+	return options.Print.SpiralVase && layerNr >= options.Print.NumberBottomLayers
+}
+
+func (s *Spiral) Render(b *gcode.Builder, layerNr int, maxLayer int, layer data.PartitionedLayer, z data.Micrometer, options *data.Options) error {
+	if !isSpiralLayer(layerNr, options) {
+		return nil
+	}
+
+	perimeters, err := modifier.Perimeters(layer)
+	if err != nil {
+		return err
+	}
+	if perimeters == nil {
+		return nil
+	}
+
+	b.SetExtrudeSpeed(options.Print.OuterPerimeterSpeed)
+
+	for _, part := range perimeters {
+		if len(part) == 0 {
+			continue
+		}
+
+		for _, outerPart := range part[0] {
+			outline := outerPart.Outline()
+			if len(outline) == 0 {
+				continue
+			}
+
+			// Start next to the end of the previous layer to avoid a visible seam.
+			if s.last != nil {
+				outline = outline.StartNearest(s.last)
+			}
+
+			b.AddComment("TYPE:WALL-OUTER")
+			err := b.AddSpiral(layer, outline, z-layer.Thickness(), z)
+			if err != nil {
+				return err
+			}
+
+			s.last = outline[0]
+		}
+	}
+
+	return nil
+}
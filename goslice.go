@@ -1,53 +1,89 @@
 package goslice
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
 	"time"
 
+	"github.com/aligator/goslice/arranger"
 	"github.com/aligator/goslice/clip"
 	"github.com/aligator/goslice/data"
 	"github.com/aligator/goslice/gcode"
 	"github.com/aligator/goslice/gcode/renderer"
 	"github.com/aligator/goslice/handler"
+	"github.com/aligator/goslice/inspector"
 	"github.com/aligator/goslice/modifier"
 	"github.com/aligator/goslice/optimizer"
 	"github.com/aligator/goslice/reader"
 	"github.com/aligator/goslice/slicer"
+	"github.com/aligator/goslice/transformer"
 	"github.com/aligator/goslice/writer"
 )
 
 // GoSlice combines all logic  needed to slice
 // a model and generate a GCode file.
 type GoSlice struct {
-	Options   data.GoSliceOptions
-	Reader    handler.ModelReader
-	Optimizer handler.ModelOptimizer
-	Slicer    handler.ModelSlicer
-	Modifiers []handler.LayerModifier
-	Generator handler.GCodeGenerator
-	Writer    handler.GCodeWriter
+	Options     data.GoSliceOptions
+	Reader      handler.ModelReader
+	Transformer handler.ModelTransformer
+	Arranger    handler.ModelArranger
+	Optimizer   handler.ModelOptimizer
+	Slicer      handler.ModelSlicer
+	Modifiers   []handler.LayerModifier
+	Generator   handler.GCodeGenerator
+	Writer      handler.GCodeWriter
+
+	// options are the options the built in handlers were created with.
+	// It is nil if GoSlice was not created by NewGoSlice.
+	options  *data.Options
+	progress *progressTracker
+}
+
+// progressTracker adds the elapsed time since the start of the processing to all progress reports.
+type progressTracker struct {
+	start  time.Time
+	report data.ProgressFunc
+}
+
+func (t *progressTracker) Report(progress data.Progress) {
+	progress.Elapsed = time.Since(t.start)
+	t.report.Report(progress)
 }
 
 // NewGoSlice provides a GoSlice with all built in implementations.
 func NewGoSlice(options data.Options) *GoSlice {
+	// All handlers report their progress through the tracker to get the elapsed time.
+	progress := &progressTracker{
+		start:  time.Now(),
+		report: options.GoSlice.Progress,
+	}
+	options.GoSlice.Progress = progress.Report
+
 	s := &GoSlice{
-		Options: options.GoSlice,
+		Options:  options.GoSlice,
+		options:  &options,
+		progress: progress,
 	}
 
 	// create handlers
-	topBottomPatternFactory := func(min data.MicroPoint, max data.MicroPoint) clip.Pattern {
-		return clip.NewLinearPattern(options.Printer.ExtrusionWidth, options.Printer.ExtrusionWidth, min, max, options.Print.InfillRotationDegree, true, false)
-	}
 
 	s.Reader = reader.Reader(&options)
+	s.Transformer = transformer.NewTransformer(&options)
+	s.Arranger = arranger.NewArranger(&options)
 	s.Optimizer = optimizer.NewOptimizer(&options)
 	s.Slicer = slicer.NewSlicer(&options)
 	s.Modifiers = []handler.LayerModifier{
 		modifier.NewPerimeterModifier(&options),
 		modifier.NewInfillModifier(&options),
 		modifier.NewInternalInfillModifier(&options),
+		modifier.NewLightningInfillModifier(&options),
 		modifier.NewBrimModifier(&options),
 		modifier.NewSupportDetectorModifier(&options),
 		modifier.NewSupportGeneratorModifier(&options),
+		modifier.NewBedValidatorModifier(&options),
 	}
 
 	patternSpacing := options.Print.Support.PatternSpacing.ToMicrometer()
@@ -58,10 +94,11 @@ func NewGoSlice(options data.Options) *GoSlice {
 		gcode.WithRenderer(renderer.Skirt{}),
 		gcode.WithRenderer(renderer.Brim{}),
 		gcode.WithRenderer(renderer.Perimeter{}),
+		gcode.WithRenderer(&renderer.Spiral{}),
 
 		// Add infill for support generation.
 		gcode.WithRenderer(&renderer.Infill{
-			PatternSetup: func(min data.MicroPoint, max data.MicroPoint) clip.Pattern {
+			PatternSetup: func(min data.MicroPoint, max data.MicroPoint, options *data.Options) clip.Pattern {
 				// make bounding box bigger to allow generation of support which has always at least two lines
 				min.SetX(min.X() - patternSpacing)
 				min.SetY(min.Y() - patternSpacing)
@@ -74,7 +111,7 @@ func NewGoSlice(options data.Options) *GoSlice {
 		}),
 		// Interface pattern for support generation is generated by rotating 90° to the support and no spaces between the lines.
 		gcode.WithRenderer(&renderer.Infill{
-			PatternSetup: func(min data.MicroPoint, max data.MicroPoint) clip.Pattern {
+			PatternSetup: func(min data.MicroPoint, max data.MicroPoint, options *data.Options) clip.Pattern {
 				// make bounding box bigger to allow generation of support which has always at least two lines
 				min.SetX(min.X() - patternSpacing)
 				min.SetY(min.Y() - patternSpacing)
@@ -87,34 +124,22 @@ func NewGoSlice(options data.Options) *GoSlice {
 		}),
 
 		gcode.WithRenderer(&renderer.Infill{
-			PatternSetup: topBottomPatternFactory,
+			PatternSetup: topBottomPatternSetup,
 			AttrName:     "bottom",
 			Comments:     []string{"TYPE:FILL", "BOTTOM-FILL"},
 		}),
 		gcode.WithRenderer(&renderer.Infill{
-			PatternSetup: topBottomPatternFactory,
+			PatternSetup: topBottomPatternSetup,
 			AttrName:     "top",
 			Comments:     []string{"TYPE:FILL", "TOP-FILL"},
 		}),
 		gcode.WithRenderer(&renderer.Infill{
-			PatternSetup: func(min data.MicroPoint, max data.MicroPoint) clip.Pattern {
-				// TODO: the calculation of the percentage is currently very basic and may not be correct.
-
-				if options.Print.InfillPercent != 0 {
-					mm10 := data.Millimeter(10).ToMicrometer()
-					linesPer10mmFor100Percent := mm10 / options.Printer.ExtrusionWidth
-					linesPer10mmForInfillPercent := float64(linesPer10mmFor100Percent) * float64(options.Print.InfillPercent) / 100.0
-
-					lineWidth := data.Micrometer(float64(mm10) / linesPer10mmForInfillPercent)
-
-					return clip.NewLinearPattern(options.Printer.ExtrusionWidth, lineWidth, min, max, options.Print.InfillRotationDegree, true, options.Print.InfillZigZag)
-				}
-
-				return nil
-			},
-			AttrName: "infill",
-			Comments: []string{"TYPE:FILL", "INTERNAL-FILL"},
+			PatternSetup: infillPatternSetup,
+			AttrName:     "infill",
+			Comments:     []string{"TYPE:FILL", "INTERNAL-FILL"},
 		}),
+		gcode.WithRenderer(renderer.Lightning{}),
+		gcode.WithRenderer(&renderer.Ironing{}),
 		gcode.WithRenderer(renderer.PostLayer{}),
 	)
 	s.Writer = writer.Writer()
@@ -122,20 +147,272 @@ func NewGoSlice(options data.Options) *GoSlice {
 	return s
 }
 
+// topBottomPatternSetup creates the pattern for the solid top and bottom layers which is selected by options.Print.TopBottomPattern.
+func topBottomPatternSetup(min data.MicroPoint, max data.MicroPoint, options *data.Options) clip.Pattern {
+	switch options.Print.TopBottomPattern {
+	case data.InfillPatternConcentric:
+		return clip.NewConcentricPattern(options.Printer.ExtrusionWidth, options.Printer.ExtrusionWidth)
+	default:
+		return clip.NewLinearPattern(options.Printer.ExtrusionWidth, options.Printer.ExtrusionWidth, min, max, options.Print.InfillRotationDegree, true, false)
+	}
+}
+
+// infillPatternSetup creates the pattern for the internal infill which is selected by options.Print.InfillPattern.
+// The distance between the lines is calculated from options.Print.InfillPercent.
+func infillPatternSetup(min data.MicroPoint, max data.MicroPoint, options *data.Options) clip.Pattern {
+	if options.Print.InfillPercent == 0 {
+		return nil
+	}
+
+	lineWidth := options.InfillLineDistance()
+
+	switch options.Print.InfillPattern {
+	case data.InfillPatternGyroid:
+		return clip.NewGyroidPattern(lineWidth)
+	case data.InfillPatternGrid:
+		return clip.NewGridPattern(lineWidth, min, max, options.Print.InfillRotationDegree)
+	case data.InfillPatternTriangles:
+		return clip.NewTrianglesPattern(lineWidth, min, max, options.Print.InfillRotationDegree)
+	case data.InfillPatternCubic:
+		return clip.NewCubicPattern(lineWidth, min, max, options.Print.InfillRotationDegree)
+	case data.InfillPatternHoneycomb:
+		return clip.NewHoneycombPattern(options.Printer.ExtrusionWidth, lineWidth, min, max, options.Print.InfillRotationDegree)
+	case data.InfillPatternConcentric:
+		return clip.NewConcentricPattern(options.Printer.ExtrusionWidth, lineWidth)
+	case data.InfillPatternLightning:
+		// The lightning infill is generated by the modifier and printed by renderer.Lightning.
+		return nil
+	default:
+		return clip.NewLinearPattern(options.Printer.ExtrusionWidth, lineWidth, min, max, options.Print.InfillRotationDegree, true, options.Print.InfillZigZag)
+	}
+}
+
+// Process reads the models from the InputFiles (or the InputFilePath) and writes the GCode to the OutputFilePath.
+// It is the same as ProcessContext with context.Background().
 func (s *GoSlice) Process() error {
-	startTime := time.Now()
+	return s.ProcessContext(context.Background())
+}
+
+// ProcessContext reads the models from the InputFiles (or the InputFilePath) and writes the GCode to the OutputFilePath.
+// If no OutputFilePath is set, the GCode is written next to the first model file.
+// Several models (or instances of them) are placed together on the bed by the Arranger.
+// The files are opened and passed to the same processing as ProcessStream, so the Reader has to implement
+// handler.ModelStreamReader.
+// If the Writer implements handler.GCodeFileCreator, the GCode is written layer by layer
+// while it is generated. Otherwise it is collected and passed to the Writer at the end.
+// The processing stops with the error of the context as soon as it gets cancelled.
+func (s *GoSlice) ProcessContext(ctx context.Context) error {
+	s.start()
+
+	files := s.Options.InputFiles
+	if len(files) == 0 {
+		files = []data.InputFile{{Path: s.Options.InputFilePath, Instances: 1}}
+	}
 
-	// 1. Load model
+	// 1. Open models
+	inputs := make([]io.Reader, len(files))
+	instances := make([]int, len(files))
+	for i, file := range files {
+		s.Options.Logger.Printf("Load model %v\n", file.Path)
+		in, err := os.Open(file.Path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		inputs[i] = in
+		instances[i] = file.Instances
+	}
+
+	outputPath := s.Options.OutputFilePath
+	if outputPath == "" {
+		outputPath = files[0].Path + ".gcode"
+	}
+
+	if creator, ok := s.Writer.(handler.GCodeFileCreator); ok {
+		out, err := creator.Create(outputPath)
+		if err != nil {
+			return err
+		}
+
+		err = s.processStreams(ctx, inputs, instances, out)
+		closeErr := out.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+
+		s.finish()
+		return nil
+	}
+
+	var finalGcode bytes.Buffer
+	err := s.processStreams(ctx, inputs, instances, &finalGcode)
+	if err != nil {
+		return err
+	}
+
+	err = s.Writer.Write(finalGcode.String(), outputPath)
+	if err != nil {
+		return err
+	}
+
+	s.finish()
+	return nil
+}
+
+// ProcessStream reads the model from in and writes the GCode to out layer by layer.
+// The file paths of the options and the Writer are not used.
+// The Reader has to implement handler.ModelStreamReader.
+// The processing stops with the error of the context as soon as it gets cancelled.
+func (s *GoSlice) ProcessStream(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.start()
+
+	s.Options.Logger.Printf("Load model from stream\n")
+	err := s.processStreams(ctx, []io.Reader{in}, []int{1}, out)
+	if err != nil {
+		return err
+	}
+
+	s.finish()
+	return nil
+}
+
+// processStreams reads one model from each input and writes the GCode to out.
+// Each model is printed as often as the instance count at the same index says.
+func (s *GoSlice) processStreams(ctx context.Context, inputs []io.Reader, instances []int, out io.Writer) error {
+	streamReader, ok := s.Reader.(handler.ModelStreamReader)
+	if !ok {
+		return errors.New("the model reader does not support reading from a stream")
+	}
+
+	// 1. Load models
+	s.report(data.Progress{Stage: data.StageRead})
+	models := make([]data.Model, len(inputs))
+	for i, in := range inputs {
+		model, err := streamReader.ReadStream(in)
+		if err != nil {
+			return err
+		}
+		models[i] = model
+	}
+
+	return s.slice(ctx, models, instances, out)
+}
+
+// Inspect reads the model from the InputFilePath and optimizes it, but does not slice it.
+// It returns the diagnostics of the optimized model, e.g. to validate uploaded models.
+// The diagnostics describe the model as it was read, so it is neither repaired nor moved onto the bed
+// and a built in optimizer is used instead of the Optimizer.
+// If the repair is enabled, the report additionally contains what the Optimizer would repair.
+func (s *GoSlice) Inspect() (inspector.Report, error) {
 	s.Options.Logger.Printf("Load model %v\n", s.Options.InputFilePath)
-	models, err := s.Reader.Read(s.Options.InputFilePath)
+	model, err := s.Reader.Read(s.Options.InputFilePath)
+	if err != nil {
+		return inspector.Report{}, err
+	}
+
+	options := data.DefaultOptions()
+	if s.options != nil {
+		options = *s.options
+	}
+	options.GoSlice = s.Options
+	options.Slicing.RepairMesh = false
+
+	// Keep the position by placing the model where it already is.
+	min, max := model.Min(), model.Max()
+	options.Printer.Center = data.NewMicroVec3((min.X()+max.X())/2, (min.Y()+max.Y())/2, min.Z())
+	options.Transform.TranslateX = 0
+	options.Transform.TranslateY = 0
+
+	optimizedModel, err := optimizer.NewOptimizer(&options).Optimize(model)
 	if err != nil {
+		return inspector.Report{}, err
+	}
+	report := inspector.Inspect(optimizedModel)
+
+	if s.options != nil && s.options.Slicing.RepairMesh {
+		repairedModel, err := s.Optimizer.Optimize(model)
+		if err != nil {
+			return inspector.Report{}, err
+		}
+		report.Repair = repairedModel.RepairReport()
+	}
+
+	return report, nil
+}
+
+// start resets the elapsed time of the progress reports.
+func (s *GoSlice) start() {
+	// GoSlice may be created without NewGoSlice.
+	if s.progress == nil {
+		s.progress = &progressTracker{report: s.Options.Progress}
+	}
+	s.progress.start = time.Now()
+}
+
+// report reports the progress of a stage which is handled by GoSlice itself.
+func (s *GoSlice) report(progress data.Progress) {
+	s.progress.Report(progress)
+}
+
+// finish reports the end of the processing.
+func (s *GoSlice) finish() {
+	s.report(data.Progress{Stage: data.StageDone})
+	s.Options.Logger.Println("full processing time:", time.Since(s.progress.start))
+}
+
+// slice runs all steps between reading the models and writing the GCode.
+// Each model is printed as often as the instance count at the same index says.
+// The generated GCode is written to out.
+func (s *GoSlice) slice(ctx context.Context, models []data.Model, instances []int, out io.Writer) error {
+	var placed []data.Model
+	for i, model := range models {
+		s.Options.Logger.Printf("Model loaded.\nFace count: %v\nSize: min: %v max %v\n", model.FaceCount(), model.Min(), model.Max())
+
+		// 2. Transform model
+		if s.Transformer != nil {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			s.report(data.Progress{Stage: data.StageTransform})
+			var err error
+			model, err = s.Transformer.Transform(model)
+			if err != nil {
+				return err
+			}
+		}
+
+		placed = append(placed, model)
+		for j := 1; j < instances[i]; j++ {
+			placed = append(placed, model)
+		}
+	}
+
+	// 3. Arrange the models on the bed
+	if err := ctx.Err(); err != nil {
 		return err
 	}
-	s.Options.Logger.Printf("Model loaded.\nFace count: %v\nSize: min: %v max %v\n", models.FaceCount(), models.Min(), models.Max())
+	model := placed[0]
+	if s.Arranger != nil {
+		s.report(data.Progress{Stage: data.StageArrange})
+		var err error
+		model, err = s.Arranger.Arrange(placed)
+		if err != nil {
+			return err
+		}
+	} else if len(placed) > 1 {
+		return errors.New("several models can only be sliced together with an arranger")
+	}
 
-	// 2. Optimize model
-	var optimizedModel data.OptimizedModel
-	optimizedModel, err = s.Optimizer.Optimize(models)
+	// 4. Optimize model
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.report(data.Progress{Stage: data.StageOptimize})
+	optimizedModel, err := s.Optimizer.Optimize(model)
 	if err != nil {
 		return err
 	}
@@ -146,19 +423,26 @@ func (s *GoSlice) Process() error {
 	//	return err
 	//}
 
-	// 3. Slice model into layers
-	layers, err := s.Slicer.Slice(optimizedModel)
+	// 5. Slice model into layers
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.report(data.Progress{Stage: data.StageSlice})
+	layers, err := s.Slicer.Slice(ctx, optimizedModel)
 	if err != nil {
 		return err
 	}
 	s.Options.Logger.Printf("Model sliced to %v layers\n", len(layers))
 
-	// 4. Modify the layers
+	// 6. Modify the layers
 	// e.g. generate perimeter paths,
 	// generate the parts which should be filled in, ...
 	for _, m := range s.Modifiers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		m.Init(optimizedModel)
-		err = m.Modify(layers)
+		err = m.Modify(ctx, layers)
 		if err != nil {
 			return err
 		}
@@ -166,20 +450,10 @@ func (s *GoSlice) Process() error {
 	}
 	s.Options.Logger.Printf("Layers modified %v\n", len(layers))
 
-	// 5. generate gcode from the layers
-	s.Generator.Init(optimizedModel)
-	finalGcode, err := s.Generator.Generate(layers)
-	if err != nil {
+	// 7. generate gcode from the layers
+	if err := ctx.Err(); err != nil {
 		return err
 	}
-
-	outputPath := s.Options.OutputFilePath
-	if outputPath == "" {
-		outputPath = s.Options.InputFilePath + ".gcode"
-	}
-
-	err = s.Writer.Write(finalGcode, outputPath)
-	s.Options.Logger.Println("full processing time:", time.Now().Sub(startTime))
-
-	return err
+	s.Generator.Init(optimizedModel)
+	return s.Generator.Generate(ctx, layers, out)
 }
@@ -1,10 +1,17 @@
 package goslice
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/modifier"
 	"github.com/aligator/goslice/util/test"
 )
 
@@ -51,6 +58,323 @@ func TestWholeSlicer(t *testing.T) {
 		test.Ok(t, err)
 	}
 }
+
+func TestProcessStream(t *testing.T) {
+	model, err := os.Open(folder + gopher)
+	test.Ok(t, err)
+	defer model.Close()
+
+	s := NewGoSlice(data.DefaultOptions())
+	var out bytes.Buffer
+	test.Ok(t, s.ProcessStream(context.Background(), model, &out))
+
+	test.Assert(t, strings.Contains(out.String(), ";END_GCODE"), "the gcode should be complete")
+}
+
+func TestProcessStreamCancelled(t *testing.T) {
+	model, err := os.Open(folder + gopher)
+	test.Ok(t, err)
+	defer model.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := NewGoSlice(data.DefaultOptions())
+	var out bytes.Buffer
+	err = s.ProcessStream(ctx, model, &out)
+	test.Assert(t, errors.Is(err, context.Canceled), "the processing should be cancelled but got %v", err)
+	test.Equals(t, 0, out.Len())
+}
+
+func TestProgress(t *testing.T) {
+	var stages []data.Stage
+	var last data.Progress
+
+	o := data.DefaultOptions()
+	o.GoSlice.Progress = func(progress data.Progress) {
+		if len(stages) == 0 || stages[len(stages)-1] != progress.Stage {
+			stages = append(stages, progress.Stage)
+		}
+
+		test.Assert(t, progress.Layer <= progress.LayerCount, "the layer %v should not be bigger than the layer count %v", progress.Layer, progress.LayerCount)
+		test.Assert(t, progress.Elapsed >= last.Elapsed, "the elapsed time should not decrease")
+		last = progress
+	}
+
+	s := NewGoSlice(o)
+	s.Options.InputFilePath = folder + gopher
+	s.Writer = &fakeWriter{}
+	test.Ok(t, s.Process())
+
+	test.Equals(t, []data.Stage{
+		data.StageRead,
+		data.StageTransform,
+		data.StageArrange,
+		data.StageOptimize,
+		data.StageSlice,
+		data.StageModify,
+		data.StageGenerate,
+		data.StageDone,
+	}, stages)
+}
+
+func TestProcessContextCancelledWhileModifying(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stages []data.Stage
+
+	o := data.DefaultOptions()
+	o.GoSlice.Progress = func(progress data.Progress) {
+		stages = append(stages, progress.Stage)
+		if progress.Stage == data.StageModify && progress.Layer == 10 {
+			cancel()
+		}
+	}
+
+	s := NewGoSlice(o)
+	s.Options.InputFilePath = folder + gopher
+	w := &fakeWriter{}
+	s.Writer = w
+
+	err := s.ProcessContext(ctx)
+	test.Assert(t, errors.Is(err, context.Canceled), "the processing should be cancelled but got %v", err)
+	test.Equals(t, data.StageModify, stages[len(stages)-1])
+	test.Assert(t, w.finalGcode == nil, "no gcode should be written")
+}
+
+func TestConcurrencyIsDeterministic(t *testing.T) {
+	var results []string
+
+	for _, concurrency := range []int{1, 4} {
+		model, err := os.Open(folder + gopher)
+		test.Ok(t, err)
+
+		o := data.DefaultOptions()
+		o.Print.Support.Enabled = true
+		o.GoSlice.Concurrency = concurrency
+		s := NewGoSlice(o)
+
+		var out bytes.Buffer
+		test.Ok(t, s.ProcessStream(context.Background(), model, &out))
+		_ = model.Close()
+
+		results = append(results, out.String())
+	}
+
+	test.Assert(t, results[0] == results[1], "the gcode should not depend on the concurrency")
+}
+
+func TestProcessSeveralInstances(t *testing.T) {
+	o := data.DefaultOptions()
+	o.Transform.Scale = 0.3
+	o.Print.BrimSkirt.BrimCount = 2
+	o.GoSlice.InputFiles = []data.InputFile{
+		{Path: folder + gopher, Instances: 2},
+		{Path: folder + gopher, Instances: 1},
+	}
+
+	s := NewGoSlice(o)
+	w := &fakeWriter{}
+	s.Writer = w
+	test.Ok(t, s.Process())
+
+	single := data.DefaultOptions()
+	single.Transform.Scale = 0.3
+	single.Print.BrimSkirt.BrimCount = 2
+	single.GoSlice.InputFilePath = folder + gopher
+
+	s = NewGoSlice(single)
+	singleWriter := &fakeWriter{}
+	s.Writer = singleWriter
+	test.Ok(t, s.Process())
+
+	test.Assert(t, len(w.finalGcode) > 2*len(singleWriter.finalGcode), "three instances should need much more gcode than one")
+}
+
+func TestBuildVolumeExceeded(t *testing.T) {
+	o := data.DefaultOptions()
+	o.Printer.BedWidth = 30
+	o.Printer.BedHeight = 50
+
+	s := NewGoSlice(o)
+	s.Options.InputFilePath = folder + gopher
+	w := &fakeWriter{}
+	s.Writer = w
+
+	err := s.Process()
+	var volumeErr *modifier.BuildVolumeError
+	test.Assert(t, errors.As(err, &volumeErr), "expected a BuildVolumeError but got %v", err)
+	test.Assert(t, w.finalGcode == nil, "no gcode should be written")
+
+	directions := map[string]bool{}
+	for _, overflow := range volumeErr.Overflows {
+		test.Assert(t, overflow.Distance > 0, "the overflow should be positive")
+		directions[overflow.Object+overflow.Direction] = true
+	}
+	test.Equals(t, map[string]bool{
+		"model-x": true,
+		"model+x": true,
+		"model+z": true,
+		"skirt-x": true,
+		"skirt+x": true,
+	}, directions)
+}
+
+func TestInspectOpenMesh(t *testing.T) {
+	// A 10 mm cube at 10, 20, 30 without its top.
+	corners := func(x, y, z float64) string {
+		return fmt.Sprintf("vertex %v %v %v\n", 10+x*10, 20+y*10, 30+z*10)
+	}
+	facets := [][3][3]float64{
+		{{0, 0, 0}, {1, 1, 0}, {1, 0, 0}},
+		{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}},
+		{{0, 0, 0}, {1, 0, 0}, {1, 0, 1}},
+		{{0, 0, 0}, {1, 0, 1}, {0, 0, 1}},
+		{{0, 1, 0}, {1, 1, 1}, {1, 1, 0}},
+		{{0, 1, 0}, {0, 1, 1}, {1, 1, 1}},
+		{{0, 0, 0}, {0, 0, 1}, {0, 1, 1}},
+		{{0, 0, 0}, {0, 1, 1}, {0, 1, 0}},
+		{{1, 0, 0}, {1, 1, 0}, {1, 1, 1}},
+		{{1, 0, 0}, {1, 1, 1}, {1, 0, 1}},
+	}
+	var stl strings.Builder
+	stl.WriteString("solid open\n")
+	for _, facet := range facets {
+		stl.WriteString("facet normal 0 0 0\nouter loop\n")
+		for _, p := range facet {
+			stl.WriteString(corners(p[0], p[1], p[2]))
+		}
+		stl.WriteString("endloop\nendfacet\n")
+	}
+	stl.WriteString("endsolid open\n")
+
+	file, err := ioutil.TempFile("", "goslice-*.stl")
+	test.Ok(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(stl.String())
+	test.Ok(t, err)
+	test.Ok(t, file.Close())
+
+	o := data.DefaultOptions()
+	o.Slicing.RepairMesh = true
+	o.GoSlice.InputFilePath = file.Name()
+
+	report, err := NewGoSlice(o).Inspect()
+	test.Ok(t, err)
+
+	// The report describes the model as it is in the file.
+	test.Equals(t, 4, report.OpenEdges)
+	test.Equals(t, 10, report.Faces)
+	test.Equals(t, [3]float64{10, 20, 30}, report.Min)
+	test.Equals(t, [3]float64{20, 30, 40}, report.Max)
+
+	// The repair would close the hole.
+	test.Equals(t, 1, report.Repair.FilledHoles)
+	test.Equals(t, 0, report.Repair.OpenEdges)
+}
+
+// gcodeOfLayer returns the lines of the given layer.
+func gcodeOfLayer(gcode string, layerNr int) string {
+	start := strings.Index(gcode, fmt.Sprintf(";LAYER:%d\n", layerNr))
+	if start == -1 {
+		return ""
+	}
+	end := strings.Index(gcode[start+1:], ";LAYER:")
+	if end == -1 {
+		return gcode[start:]
+	}
+	return gcode[start : start+1+end]
+}
+
+func TestHeightOverrides(t *testing.T) {
+	model, err := os.Open(folder + gopher)
+	test.Ok(t, err)
+	defer model.Close()
+
+	o := data.DefaultOptions()
+	test.Ok(t, o.Overrides.Set("5-8:fan-speed=100,hot-end-temperature=230,infill-percent=0"))
+
+	s := NewGoSlice(o)
+	var out bytes.Buffer
+	test.Ok(t, s.ProcessStream(context.Background(), model, &out))
+	gcode := out.String()
+
+	// The layers 24 (z = 5 mm) to 38 (z = 7.8 mm) are inside of the override.
+	test.Assert(t, !strings.Contains(gcodeOfLayer(gcode, 23), "M106 S100"), "the fan speed should not be changed below the override")
+	test.Assert(t, strings.Contains(gcodeOfLayer(gcode, 24), "M106 S100"), "the fan speed should be changed at the start of the override")
+	test.Assert(t, strings.Contains(gcodeOfLayer(gcode, 24), "M104 S230"), "the temperature should be changed at the start of the override")
+	test.Assert(t, strings.Contains(gcodeOfLayer(gcode, 20), "INTERNAL-FILL"), "the infill should be generated below the override")
+	test.Assert(t, !strings.Contains(gcodeOfLayer(gcode, 30), "INTERNAL-FILL"), "the infill should be disabled inside of the override")
+
+	layer39 := gcodeOfLayer(gcode, 39)
+	test.Assert(t, strings.Contains(layer39, fmt.Sprintf("M106 S%d", o.Filament.FanSpeed.SpeedAt(39))), "the fan speed should be restored after the override")
+	test.Assert(t, strings.Contains(layer39, fmt.Sprintf("M104 S%d", o.Filament.HotEndTemperature)), "the temperature should be restored after the override")
+}
+
+func TestSpiralVase(t *testing.T) {
+	model, err := os.Open(folder + gopher)
+	test.Ok(t, err)
+	defer model.Close()
+
+	o := data.DefaultOptions()
+	o.Print.SpiralVase = true
+
+	s := NewGoSlice(o)
+	var out bytes.Buffer
+	test.Ok(t, s.ProcessStream(context.Background(), model, &out))
+	gcode := out.String()
+
+	bottom := gcodeOfLayer(gcode, o.Print.NumberBottomLayers-1)
+	test.Assert(t, strings.Contains(bottom, "BOTTOM-FILL"), "the bottom layers should be filled")
+	test.Assert(t, strings.Contains(bottom, "WALL-INNER"), "the bottom layers should have all perimeters")
+
+	spiral := gcodeOfLayer(gcode, 100)
+	test.Assert(t, strings.Contains(spiral, "WALL-OUTER"), "the spiral layers should have the outer perimeter")
+	test.Assert(t, !strings.Contains(spiral, "WALL-INNER"), "the spiral layers should have no inner perimeters")
+	test.Assert(t, !strings.Contains(spiral, "FILL"), "the spiral layers should have no infill")
+}
+
+func TestIroning(t *testing.T) {
+	model, err := os.Open(folder + gopher)
+	test.Ok(t, err)
+	defer model.Close()
+
+	o := data.DefaultOptions()
+	o.Print.Ironing.Enabled = true
+
+	s := NewGoSlice(o)
+	var out bytes.Buffer
+	test.Ok(t, s.ProcessStream(context.Background(), model, &out))
+	gcode := out.String()
+
+	top := gcodeOfLayer(gcode, strings.Count(gcode, ";LAYER:")-1)
+	test.Assert(t, strings.Contains(top, ";TYPE:IRONING"), "the topmost layer should be ironed")
+	test.Assert(t, strings.Contains(top, fmt.Sprintf(" F%v ", int(o.Print.Ironing.Speed)*60)), "the ironing speed should be used")
+	test.Assert(t, !strings.Contains(gcodeOfLayer(gcode, 100), ";TYPE:IRONING"), "layers without a top surface should not be ironed")
+}
+
+func TestLightningInfill(t *testing.T) {
+	slice := func(pattern data.InfillPattern) string {
+		model, err := os.Open(folder + gopher)
+		test.Ok(t, err)
+		defer model.Close()
+
+		o := data.DefaultOptions()
+		o.Print.InfillPattern = pattern
+
+		s := NewGoSlice(o)
+		var out bytes.Buffer
+		test.Ok(t, s.ProcessStream(context.Background(), model, &out))
+		return out.String()
+	}
+
+	linear := strings.Count(slice(data.InfillPatternLinear), ";INTERNAL-FILL")
+	lightning := strings.Count(slice(data.InfillPatternLightning), ";INTERNAL-FILL")
+	test.Assert(t, lightning > 0, "the top layers should be supported by the lightning infill")
+	test.Assert(t, lightning < linear, "the lightning infill should only be generated below the top layers")
+}
+
 type fakeWriter struct {
 	finalGcode []string
 }
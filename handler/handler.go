@@ -2,7 +2,12 @@
 
 package handler
 
-import "github.com/aligator/goslice/data"
+import (
+	"context"
+	"io"
+
+	"github.com/aligator/goslice/data"
+)
 
 type Namer interface {
 	GetName() string
@@ -21,32 +26,65 @@ type ModelReader interface {
 	Read(filename string) (data.Model, error)
 }
 
+// ModelStreamReader reads a model from a stream.
+// As there is no file name, the format has to be detected by the content.
+type ModelStreamReader interface {
+	ReadStream(r io.Reader) (data.Model, error)
+}
+
+// ModelTransformer can transform a model (e.g. scale or rotate it) before it gets optimized.
+type ModelTransformer interface {
+	Transform(m data.Model) (data.Model, error)
+}
+
+// ModelArranger places several models on the bed and merges them into one model.
+// The models are already transformed.
+// The same model may be passed several times to print several instances of it.
+type ModelArranger interface {
+	Arrange(models []data.Model) (data.Model, error)
+}
+
 // ModelOptimizer can optimize a model and generates an optimized model out of it.
 type ModelOptimizer interface {
 	Optimize(m data.Model) (data.OptimizedModel, error)
 }
 
 // ModelSlicer can slice an optimized model into several layers.
+// It should stop and return the error of the context if it gets cancelled.
 type ModelSlicer interface {
-	Slice(m data.OptimizedModel) ([]data.PartitionedLayer, error)
+	Slice(ctx context.Context, m data.OptimizedModel) ([]data.PartitionedLayer, error)
 }
 
 // LayerModifier can add new attributes to the layers or even alter the layer directly.
+// It should stop and return the error of the context if it gets cancelled.
 type LayerModifier interface {
 	Namer
 	Init(m data.OptimizedModel)
-	Modify(layers []data.PartitionedLayer) error
+	Modify(ctx context.Context, layers []data.PartitionedLayer) error
 }
 
-// GCodeGenerator generates the GCode out of the given layers.
+// GCodeGenerator generates the GCode out of the given layers and writes it to w.
 // The layers are already modified by the layer modifiers.
 // So the attributes added by them can be used.
+// It should stop and return the error of the context if it gets cancelled.
 type GCodeGenerator interface {
 	Init(m data.OptimizedModel)
-	Generate(layer []data.PartitionedLayer) (string, error)
+	Generate(ctx context.Context, layer []data.PartitionedLayer, w io.Writer) error
 }
 
 // GCodeWriter writes the given GCode into the given destination.
 type GCodeWriter interface {
 	Write(gcode string, destination string) error
 }
+
+// GCodeFileCreator can be implemented additionally to GCodeWriter.
+// It creates the destination in advance, so that the GCode can be written
+// while it is generated instead of holding all of it in memory.
+type GCodeFileCreator interface {
+	Create(destination string) (io.WriteCloser, error)
+}
+
+// GCodeStreamWriter writes the given GCode into a stream.
+type GCodeStreamWriter interface {
+	WriteStream(gcode string, w io.Writer) error
+}
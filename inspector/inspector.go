@@ -0,0 +1,167 @@
+// Package inspector provides diagnostics of optimized models.
+//
+// All values are calculated from the faces and their touching faces of the data.OptimizedModel:
+// * Vertices are all distinct points used by the faces.
+// * Open edges are edges without a touching face.
+// * Non-manifold edges are edges which are used by more than two faces.
+// * Shells are the groups of faces which are connected through their touching faces.
+// * The volume is the sum of the signed volumes of the tetrahedrons between each face and the origin.
+//   It is only correct for closed models with a consistent face orientation and negative if all faces point inwards.
+
+package inspector
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/aligator/goslice/data"
+)
+
+// Report contains the diagnostics of a model.
+// All lengths are in mm, the surface area in mm² and the volume in mm³.
+type Report struct {
+	Faces            int               `json:"faces"`
+	Vertices         int               `json:"vertices"`
+	Min              [3]float64        `json:"min"`
+	Max              [3]float64        `json:"max"`
+	Size             [3]float64        `json:"size"`
+	Volume           float64           `json:"volume"`
+	SurfaceArea      float64           `json:"surfaceArea"`
+	OpenEdges        int               `json:"openEdges"`
+	NonManifoldEdges int               `json:"nonManifoldEdges"`
+	Shells           int               `json:"shells"`
+	Repair           data.RepairReport `json:"repair"`
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "Faces:              %d\n", r.Faces)
+	_, _ = fmt.Fprintf(&b, "Vertices:           %d\n", r.Vertices)
+	_, _ = fmt.Fprintf(&b, "Bounding box:       %.3f, %.3f, %.3f to %.3f, %.3f, %.3f mm\n", r.Min[0], r.Min[1], r.Min[2], r.Max[0], r.Max[1], r.Max[2])
+	_, _ = fmt.Fprintf(&b, "Size:               %.3f x %.3f x %.3f mm\n", r.Size[0], r.Size[1], r.Size[2])
+	_, _ = fmt.Fprintf(&b, "Volume:             %.3f mm³\n", r.Volume)
+	_, _ = fmt.Fprintf(&b, "Surface area:       %.3f mm²\n", r.SurfaceArea)
+	_, _ = fmt.Fprintf(&b, "Open edges:         %d\n", r.OpenEdges)
+	_, _ = fmt.Fprintf(&b, "Non-manifold edges: %d\n", r.NonManifoldEdges)
+	_, _ = fmt.Fprintf(&b, "Shells:             %d\n", r.Shells)
+	_, _ = fmt.Fprintf(&b, "Repair:             %v\n", r.Repair)
+	return b.String()
+}
+
+// vertex is a point which can be used as map key.
+type vertex [3]data.Micrometer
+
+func newVertex(p data.MicroVec3) vertex {
+	return vertex{p.X(), p.Y(), p.Z()}
+}
+
+// toMillimeter converts the point to mm.
+// It does not use Micrometer.ToMillimeter to avoid the rounding errors of float32.
+func toMillimeter(p data.MicroVec3) [3]float64 {
+	return [3]float64{float64(p.X()) / 1000, float64(p.Y()) / 1000, float64(p.Z()) / 1000}
+}
+
+// edge is an edge between two vertices, always with the smaller vertex first.
+type edge [2]vertex
+
+func newEdge(a, b vertex) edge {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] > b[i] {
+				return edge{b, a}
+			}
+			break
+		}
+	}
+	return edge{a, b}
+}
+
+// Inspect calculates the diagnostics of the model.
+func Inspect(m data.OptimizedModel) Report {
+	r := Report{
+		Faces:  m.FaceCount(),
+		Repair: m.RepairReport(),
+	}
+
+	if m.FaceCount() == 0 {
+		return r
+	}
+
+	min, max := m.Min(), m.Max()
+	r.Min = toMillimeter(min)
+	r.Max = toMillimeter(max)
+	r.Size = toMillimeter(max.Sub(min))
+
+	vertices := map[vertex]bool{}
+	edges := map[edge]int{}
+	for i := 0; i < m.FaceCount(); i++ {
+		face := m.OptimizedFace(i)
+		points := face.Points()
+
+		var v [3]vertex
+		var p [3][3]float64
+		for k, point := range points {
+			v[k] = newVertex(point)
+			vertices[v[k]] = true
+			p[k] = toMillimeter(point)
+		}
+
+		for k, touching := range face.TouchingFaceIndices() {
+			edges[newEdge(v[k], v[(k+1)%3])]++
+			if touching == -1 {
+				r.OpenEdges++
+			}
+		}
+
+		// The cross product of two edges has the length of twice the area of the face.
+		a := [3]float64{p[1][0] - p[0][0], p[1][1] - p[0][1], p[1][2] - p[0][2]}
+		b := [3]float64{p[2][0] - p[0][0], p[2][1] - p[0][1], p[2][2] - p[0][2]}
+		cross := [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+		r.SurfaceArea += math.Sqrt(cross[0]*cross[0]+cross[1]*cross[1]+cross[2]*cross[2]) / 2
+
+		// Signed volume of the tetrahedron between the face and the origin: p0 · (p1 x p2) / 6
+		r.Volume += (p[0][0]*(p[1][1]*p[2][2]-p[1][2]*p[2][1]) +
+			p[0][1]*(p[1][2]*p[2][0]-p[1][0]*p[2][2]) +
+			p[0][2]*(p[1][0]*p[2][1]-p[1][1]*p[2][0])) / 6
+	}
+
+	r.Vertices = len(vertices)
+	for _, count := range edges {
+		if count > 2 {
+			r.NonManifoldEdges++
+		}
+	}
+
+	r.Shells = countShells(m)
+
+	return r
+}
+
+// countShells counts the groups of faces which are connected through their touching faces.
+func countShells(m data.OptimizedModel) int {
+	visited := make([]bool, m.FaceCount())
+	shells := 0
+	for start := range visited {
+		if visited[start] {
+			continue
+		}
+		shells++
+
+		visited[start] = true
+		stack := []int{start}
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			for _, touching := range m.OptimizedFace(current).TouchingFaceIndices() {
+				if touching != -1 && !visited[touching] {
+					visited[touching] = true
+					stack = append(stack, touching)
+				}
+			}
+		}
+	}
+
+	return shells
+}
@@ -0,0 +1,87 @@
+package inspector_test
+
+import (
+	"io/ioutil"
+	"log"
+	"math"
+	"testing"
+
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/inspector"
+	"github.com/aligator/goslice/optimizer"
+	"github.com/aligator/goslice/util/test"
+)
+
+func TestInspect(t *testing.T) {
+	var tests = map[string]struct {
+		faces    []data.Face
+		expected inspector.Report
+	}{
+		"cube": {
+			faces: test.CubeFaces(data.NewMicroVec3(0, 0, 0), 10000),
+			expected: inspector.Report{
+				Faces:       12,
+				Vertices:    8,
+				Size:        [3]float64{10, 10, 10},
+				Volume:      1000,
+				SurfaceArea: 600,
+				Shells:      1,
+			},
+		},
+		"two cubes": {
+			faces: append(test.CubeFaces(data.NewMicroVec3(0, 0, 0), 10000), test.CubeFaces(data.NewMicroVec3(20000, 0, 0), 5000)...),
+			expected: inspector.Report{
+				Faces:       24,
+				Vertices:    16,
+				Size:        [3]float64{25, 10, 10},
+				Volume:      1125,
+				SurfaceArea: 750,
+				Shells:      2,
+			},
+		},
+		"open cube": {
+			faces: test.CubeFaces(data.NewMicroVec3(0, 0, 0), 10000)[2:],
+			expected: inspector.Report{
+				Faces:       10,
+				Vertices:    8,
+				Size:        [3]float64{10, 10, 10},
+				Volume:      1000,
+				SurfaceArea: 500,
+				OpenEdges:   4,
+				Shells:      1,
+			},
+		},
+		"cubes touching at an edge": {
+			faces: append(test.CubeFaces(data.NewMicroVec3(0, 0, 0), 10000), test.CubeFaces(data.NewMicroVec3(10000, 10000, 0), 10000)...),
+			expected: inspector.Report{
+				Faces:            24,
+				Vertices:         14,
+				Size:             [3]float64{20, 20, 10},
+				Volume:           2000,
+				SurfaceArea:      1200,
+				NonManifoldEdges: 1,
+				Shells:           2,
+			},
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log("test case:", name)
+
+		options := data.DefaultOptions()
+		options.GoSlice.Logger = log.New(ioutil.Discard, "", 0)
+		options.Slicing.RepairMesh = false
+
+		m, err := optimizer.NewOptimizer(&options).Optimize(data.NewBasicModel(testCase.faces))
+		test.Ok(t, err)
+
+		report := inspector.Inspect(m)
+		// The position depends on the printer center.
+		report.Min = [3]float64{}
+		report.Max = [3]float64{}
+		report.Repair = data.RepairReport{}
+		report.Volume = math.Round(report.Volume*1000) / 1000
+		report.SurfaceArea = math.Round(report.SurfaceArea*1000) / 1000
+		test.Equals(t, testCase.expected, report)
+	}
+}
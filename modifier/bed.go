@@ -0,0 +1,208 @@
+// This file provides a modifier which checks if the print fits into the build volume of the printer.
+
+package modifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/aligator/goslice/clip"
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/handler"
+)
+
+// BedOverflow describes how far a part of the print exceeds the build volume in one direction.
+type BedOverflow struct {
+	// Object is the part of the print which exceeds the build volume: "model", "brim", "skirt" or "support".
+	Object string
+
+	// Direction is one of "-x", "+x", "-y", "+y", "+z" or "radius" for circular beds.
+	Direction string
+
+	// Distance is how far the object exceeds the build volume.
+	Distance data.Micrometer
+
+	// Layer is the first layer with the biggest overflow.
+	Layer int
+}
+
+// BuildVolumeError is returned if the print does not fit into the build volume.
+// It contains the biggest overflow for each object and direction.
+type BuildVolumeError struct {
+	Overflows []BedOverflow
+}
+
+func (e *BuildVolumeError) Error() string {
+	var overflows []string
+	for _, o := range e.Overflows {
+		overflows = append(overflows, fmt.Sprintf("%s by %v mm in %s (layer %d)", o.Object, o.Distance.ToMillimeter(), o.Direction, o.Layer))
+	}
+	return "the print exceeds the build volume: " + strings.Join(overflows, ", ")
+}
+
+type bedValidatorModifier struct {
+	handler.Named
+	options *data.Options
+}
+
+func (m bedValidatorModifier) Init(model data.OptimizedModel) {}
+
+// NewBedValidatorModifier checks if the model, skirt, brim and support fit into the build volume
+// defined by the bed size of the printer options.
+// It returns a *BuildVolumeError containing the exact overflow if not.
+// It does not change the layers and has to run after all other modifiers.
+func NewBedValidatorModifier(options *data.Options) handler.LayerModifier {
+	return &bedValidatorModifier{
+		Named: handler.Named{
+			Name: "BedValidator",
+		},
+		options: options,
+	}
+}
+
+// SkirtDistance returns the distance between the hull around the perimeters and support of the first layer
+// and the most inner skirt line.
+func SkirtDistance(options *data.Options) data.Micrometer {
+	// Skirt distance + (1/2 extrusion with of the model side + 1/2 extrusion width of the most inner brim line) + the brim width
+	// is the distance between the perimeter (or brim) and skirt.
+	return options.Print.BrimSkirt.SkirtDistance.ToMicrometer() + (options.Printer.ExtrusionWidth * data.Micrometer(options.Print.BrimSkirt.BrimCount)) + options.Printer.ExtrusionWidth
+}
+
+// bedChecker collects the biggest overflows.
+type bedChecker struct {
+	options   *data.Options
+	overflows []BedOverflow
+}
+
+// add saves the overflow if it is bigger than the already known one for the same object and direction.
+func (c *bedChecker) add(object string, direction string, distance data.Micrometer, layerNr int) {
+	if distance <= 0 {
+		return
+	}
+
+	for i, o := range c.overflows {
+		if o.Object == object && o.Direction == direction {
+			if distance > o.Distance {
+				c.overflows[i].Distance = distance
+				c.overflows[i].Layer = layerNr
+			}
+			return
+		}
+	}
+
+	c.overflows = append(c.overflows, BedOverflow{
+		Object:    object,
+		Direction: direction,
+		Distance:  distance,
+		Layer:     layerNr,
+	})
+}
+
+// check checks if all points fit onto the bed if they are exset by the margin.
+func (c *bedChecker) check(object string, layerNr int, points data.Path, margin data.Micrometer) {
+	if len(points) == 0 {
+		return
+	}
+
+	printer := c.options.Printer
+	center := printer.Center
+
+	if printer.CircularBed {
+		if printer.BedWidth == 0 {
+			return
+		}
+
+		radius := printer.BedWidth.ToMicrometer() / 2
+		max := 0.0
+		for _, p := range points {
+			max = math.Max(max, math.Hypot(float64(p.X()-center.X()), float64(p.Y()-center.Y())))
+		}
+		c.add(object, "radius", data.Micrometer(math.Ceil(max))+margin-radius, layerNr)
+		return
+	}
+
+	min, max := points.Bounds()
+	if printer.BedWidth != 0 {
+		halfWidth := printer.BedWidth.ToMicrometer() / 2
+		c.add(object, "-x", center.X()-halfWidth-(min.X()-margin), layerNr)
+		c.add(object, "+x", max.X()+margin-(center.X()+halfWidth), layerNr)
+	}
+	if printer.BedDepth != 0 {
+		halfDepth := printer.BedDepth.ToMicrometer() / 2
+		c.add(object, "-y", center.Y()-halfDepth-(min.Y()-margin), layerNr)
+		c.add(object, "+y", max.Y()+margin-(center.Y()+halfDepth), layerNr)
+	}
+}
+
+// outlines returns all points of the outlines of the parts.
+func outlines(parts []data.LayerPart) data.Path {
+	var points data.Path
+	for _, part := range parts {
+		points = append(points, part.Outline()...)
+	}
+	return points
+}
+
+func (m bedValidatorModifier) Modify(ctx context.Context, layers []data.PartitionedLayer) error {
+	checker := &bedChecker{options: m.options}
+
+	for layerNr, layer := range layers {
+		if err := startLayer(ctx, m.options, m.GetName(), layerNr, len(layers)); err != nil {
+			return err
+		}
+
+		checker.check("model", layerNr, outlines(layer.LayerParts()), 0)
+
+		support, err := FullSupport(layer)
+		if err != nil {
+			return err
+		}
+		checker.check("support", layerNr, outlines(support), 0)
+
+		if layerNr != 0 {
+			continue
+		}
+
+		brim, err := BrimOuterDimension(layer)
+		if err != nil {
+			return err
+		}
+		checker.check("brim", layerNr, outlines(brim), 0)
+
+		if m.options.Print.BrimSkirt.SkirtCount > 0 {
+			perimeters, err := Perimeters(layer)
+			if err != nil {
+				return err
+			}
+
+			// Generate the skirt lines the same way as renderer.Skirt does.
+			// Their outer edge is half a line width outside of them.
+			parts := append(append([]data.LayerPart{}, support...), perimeters.ToOneDimension()...)
+			if len(parts) > 0 {
+				c := clip.NewClipper()
+				hull, ok := c.Hull(parts)
+				if !ok {
+					return errors.New("could not generate hull around all perimeters to check the skirt")
+				}
+
+				skirt := c.Inset(data.NewBasicLayerPart(hull, nil), -m.options.Printer.ExtrusionWidth, m.options.Print.BrimSkirt.SkirtCount, SkirtDistance(m.options))
+				for _, wall := range skirt {
+					checker.check("skirt", layerNr, outlines(wall), m.options.Printer.ExtrusionWidth/2)
+				}
+			}
+		}
+	}
+
+	if m.options.Printer.BedHeight != 0 && len(layers) > 0 {
+		checker.add("model", "+z", layers[len(layers)-1].Z()-m.options.Printer.BedHeight.ToMicrometer(), len(layers)-1)
+	}
+
+	if len(checker.overflows) > 0 {
+		return &BuildVolumeError{Overflows: checker.overflows}
+	}
+
+	return nil
+}
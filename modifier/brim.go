@@ -1,6 +1,7 @@
 package modifier
 
 import (
+	"context"
 	"fmt"
 	"github.com/aligator/goslice/clip"
 	"github.com/aligator/goslice/data"
@@ -70,11 +71,16 @@ func BrimOuterDimension(layer data.PartitionedLayer) ([]data.LayerPart, error) {
 	return nil, nil
 }
 
-func (m brimModifier) Modify(layers []data.PartitionedLayer) error {
+func (m brimModifier) Modify(ctx context.Context, layers []data.PartitionedLayer) error {
 	if m.options.Print.BrimSkirt.BrimCount == 0 {
 		return nil
 	}
 
+	// The brim is only generated for the first layer.
+	if err := startLayer(ctx, m.options, m.GetName(), 0, 1); err != nil {
+		return err
+	}
+
 	layer := layers[0]
 
 	// Get the perimeters to base the brim on them.
@@ -1,6 +1,7 @@
 package modifier
 
 import (
+	"context"
 	"errors"
 	"github.com/aligator/goslice/clip"
 	"github.com/aligator/goslice/data"
@@ -14,7 +15,8 @@ type infillModifier struct {
 
 func (m infillModifier) Init(model data.OptimizedModel) {}
 
-// NewInfillModifier calculates the areas which need infill and passes them as "bottom" attribute to the layer.
+// NewInfillModifier calculates the areas which need infill and passes them as "bottom" and "top" attribute to the layer.
+// If ironing is enabled, the parts of the top areas which are the topmost surface are passed as "ironing" attribute.
 func NewInfillModifier(options *data.Options) handler.LayerModifier {
 	return &infillModifier{
 		Named: handler.Named{
@@ -40,18 +42,51 @@ func TopInfill(layer data.PartitionedLayer) ([]data.LayerPart, error) {
 	return PartsAttribute(layer, "top")
 }
 
-func (m infillModifier) Modify(layers []data.PartitionedLayer) error {
+// Ironing extracts the attribute "ironing" from the layer.
+// If it has the wrong type, a error is returned.
+// If it doesn't exist, (nil, nil) is returned.
+// If it exists, the areas to iron are returned.
+func Ironing(layer data.PartitionedLayer) ([]data.LayerPart, error) {
+	return PartsAttribute(layer, "ironing")
+}
+
+func (m infillModifier) Modify(ctx context.Context, layers []data.PartitionedLayer) error {
+	// The infill is only calculated up to the first layer without perimeters.
+	layerCount := len(layers)
 	for layerNr := range layers {
 		overlappingPerimeters, err := OverlapPerimeters(layers[layerNr])
-		if err != nil || overlappingPerimeters == nil {
+		if err != nil {
 			return err
 		}
 
 		perimeters, err := Perimeters(layers[layerNr])
-		if err != nil || perimeters == nil {
+		if overlappingPerimeters != nil && err != nil {
 			return err
 		}
 
+		if overlappingPerimeters == nil || perimeters == nil {
+			layerCount = layerNr
+			break
+		}
+	}
+
+	// In spiral vase mode only the bottom layers get infill.
+	if m.options.Print.SpiralVase && layerCount > m.options.Print.NumberBottomLayers {
+		layerCount = m.options.Print.NumberBottomLayers
+	}
+
+	// Each layer only reads the parts of the other layers, so all layers can be processed concurrently.
+	return forEachLayer(ctx, m.options, m.GetName(), layers, layerCount, func(layerNr int, options *data.Options) (data.PartitionedLayer, error) {
+		overlappingPerimeters, err := OverlapPerimeters(layers[layerNr])
+		if err != nil {
+			return nil, err
+		}
+
+		perimeters, err := Perimeters(layers[layerNr])
+		if err != nil {
+			return nil, err
+		}
+
 		var bottomInfill []data.LayerPart
 		var topInfill []data.LayerPart
 
@@ -67,7 +102,7 @@ func (m infillModifier) Modify(layers []data.PartitionedLayer) error {
 
 				// TODO: maybe merge these two loops in one function somehow?
 				// calculate the difference with the layers bellow.
-				for i := 0; i < m.options.Print.NumberBottomLayers; i++ {
+				for i := 0; i < options.Print.NumberBottomLayers; i++ {
 					var parts []data.LayerPart
 					if layerNr-i == 0 {
 						// if it's the first layer, use the whole layer
@@ -79,7 +114,7 @@ func (m infillModifier) Modify(layers []data.PartitionedLayer) error {
 						// else calculate the difference and use it
 						parts, err = partDifference(insetPart, layers[layerNr-1-i])
 						if err != nil {
-							return err
+							return nil, err
 						}
 					}
 
@@ -90,13 +125,13 @@ func (m infillModifier) Modify(layers []data.PartitionedLayer) error {
 						var ok bool
 						bottomInfillParts, ok = c.Union(bottomInfillParts, parts)
 						if !ok {
-							return errors.New("could not union bottom parts")
+							return nil, errors.New("could not union bottom parts")
 						}
 					}
 				}
 
 				// calculate the difference with the layers above
-				for i := 0; i < m.options.Print.NumberTopLayers; i++ {
+				for i := 0; i < options.Print.NumberTopLayers; i++ {
 					var parts []data.LayerPart
 					if layerNr+i == len(layers)-1 {
 						// if it's the last layer, use the whole layer
@@ -108,7 +143,7 @@ func (m infillModifier) Modify(layers []data.PartitionedLayer) error {
 						// else calculate the difference and use it
 						parts, err = partDifference(insetPart, layers[layerNr+1+i])
 						if err != nil {
-							return err
+							return nil, err
 						}
 					}
 
@@ -119,27 +154,27 @@ func (m infillModifier) Modify(layers []data.PartitionedLayer) error {
 						var ok bool
 						topInfillParts, ok = c.Union(topInfillParts, parts)
 						if !ok {
-							return errors.New("could not union top parts")
+							return nil, errors.New("could not union top parts")
 						}
 					}
 				}
 
 				// 2. Exset the area which needs infill to generate the internal overlap of top and bottom layer.
-				fullOverlapPercentage := m.options.Print.InfillOverlapPercent + m.options.Print.AdditionalInternalInfillOverlapPercent
+				fullOverlapPercentage := options.Print.InfillOverlapPercent + options.Print.AdditionalInternalInfillOverlapPercent
 				var internalOverlappingBottomParts, internalOverlappingTopParts []data.LayerPart
 				for _, bottomPart := range bottomInfillParts {
-					overlappingParts, err := calculateOverlapPerimeter(bottomPart, fullOverlapPercentage, m.options.Printer.ExtrusionWidth)
+					overlappingParts, err := calculateOverlapPerimeter(bottomPart, fullOverlapPercentage, options.Printer.ExtrusionWidth)
 					if err != nil {
-						return err
+						return nil, err
 					}
 
 					internalOverlappingBottomParts = append(internalOverlappingBottomParts, overlappingParts...)
 				}
 
 				for _, topPart := range topInfillParts {
-					overlappingParts, err := calculateOverlapPerimeter(topPart, fullOverlapPercentage, m.options.Printer.ExtrusionWidth)
+					overlappingParts, err := calculateOverlapPerimeter(topPart, fullOverlapPercentage, options.Printer.ExtrusionWidth)
 					if err != nil {
-						return err
+						return nil, err
 					}
 
 					internalOverlappingTopParts = append(internalOverlappingTopParts, overlappingParts...)
@@ -149,12 +184,12 @@ func (m infillModifier) Modify(layers []data.PartitionedLayer) error {
 				if internalOverlappingBottomParts != nil {
 					clippedParts, ok := c.Intersection(internalOverlappingBottomParts, overlappingPerimeters[partNr])
 					if !ok {
-						return errors.New("error while intersecting infill areas by the overlapping border")
+						return nil, errors.New("error while intersecting infill areas by the overlapping border")
 					}
 
 					u, ok := c.Union(bottomInfill, clippedParts)
 					if !ok {
-						return errors.New("error while calculating the union of new infill with already existing one")
+						return nil, errors.New("error while calculating the union of new infill with already existing one")
 					}
 					bottomInfill = u
 				}
@@ -162,21 +197,35 @@ func (m infillModifier) Modify(layers []data.PartitionedLayer) error {
 				if internalOverlappingTopParts != nil {
 					clippedParts, ok := c.Intersection(internalOverlappingTopParts, overlappingPerimeters[partNr])
 					if !ok {
-						return errors.New("error while intersecting infill areas by the overlapping border")
+						return nil, errors.New("error while intersecting infill areas by the overlapping border")
 					}
 					u, ok := c.Union(topInfill, clippedParts)
 					if !ok {
-						return errors.New("error while calculating the union of new infill with already existing one")
+						return nil, errors.New("error while calculating the union of new infill with already existing one")
 					}
 					topInfill = u
 				}
 			}
 		}
 
+		// Only the top areas which have nothing directly above are the surface of the model.
+		var ironing []data.LayerPart
+		if options.Print.Ironing.Enabled && len(topInfill) > 0 {
+			if layerNr == len(layers)-1 {
+				ironing = topInfill
+			} else {
+				var ok bool
+				ironing, ok = c.Difference(topInfill, layers[layerNr+1].LayerParts())
+				if !ok {
+					return nil, errors.New("error while calculating the difference of the top infill with the layer above")
+				}
+			}
+		}
+
 		if len(topInfill) > 0 && len(bottomInfill) > 0 {
 			diff, ok := c.Difference(topInfill, bottomInfill)
 			if !ok {
-				return errors.New("error while calculating the difference of new top infill with the bottom infill to avoid duplicates")
+				return nil, errors.New("error while calculating the difference of new top infill with the bottom infill to avoid duplicates")
 			}
 			topInfill = diff
 		}
@@ -188,10 +237,10 @@ func (m infillModifier) Modify(layers []data.PartitionedLayer) error {
 		if len(topInfill) > 0 {
 			newLayer.attributes["top"] = topInfill
 		}
+		if len(ironing) > 0 {
+			newLayer.attributes["ironing"] = ironing
+		}
 
-		layers[layerNr] = newLayer
-
-	}
-
-	return nil
+		return newLayer, nil
+	})
 }
@@ -1,6 +1,7 @@
 package modifier
 
 import (
+	"context"
 	"errors"
 	"github.com/aligator/goslice/clip"
 	"github.com/aligator/goslice/data"
@@ -24,21 +25,41 @@ func NewInternalInfillModifier(options *data.Options) handler.LayerModifier {
 	}
 }
 
-func (m internalInfillModifier) Modify(layers []data.PartitionedLayer) error {
+func (m internalInfillModifier) Modify(ctx context.Context, layers []data.PartitionedLayer) error {
+	// The infill is only calculated up to the first layer without perimeters.
+	layerCount := len(layers)
 	for layerNr := range layers {
 		overlappingPerimeters, err := OverlapPerimeters(layers[layerNr])
-		if err != nil || overlappingPerimeters == nil {
+		if err != nil {
 			return err
 		}
 
+		if overlappingPerimeters == nil {
+			layerCount = layerNr
+			break
+		}
+	}
+
+	// In spiral vase mode only the bottom layers get infill.
+	if m.options.Print.SpiralVase && layerCount > m.options.Print.NumberBottomLayers {
+		layerCount = m.options.Print.NumberBottomLayers
+	}
+
+	// Each layer only depends on itself, so all layers can be processed concurrently.
+	return forEachLayer(ctx, m.options, m.GetName(), layers, layerCount, func(layerNr int, options *data.Options) (data.PartitionedLayer, error) {
+		overlappingPerimeters, err := OverlapPerimeters(layers[layerNr])
+		if err != nil {
+			return nil, err
+		}
+
 		bottomInfill, err := BottomInfill(layers[layerNr])
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		topInfill, err := TopInfill(layers[layerNr])
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		var internalInfill []data.LayerPart
@@ -51,7 +72,7 @@ func (m internalInfillModifier) Modify(layers []data.PartitionedLayer) error {
 			// to get the internal infill areas.
 
 			// if no infill, just ignore the generation
-			if m.options.Print.InfillPercent == 0 {
+			if options.Print.InfillPercent == 0 {
 				continue
 			}
 
@@ -62,7 +83,7 @@ func (m internalInfillModifier) Modify(layers []data.PartitionedLayer) error {
 
 			parts, ok := c.Difference(overlappingPart, append(bottomInfill, topInfill...))
 			if !ok {
-				return errors.New("error while calculating the difference between the max overlap border and the bottom infill")
+				return nil, errors.New("error while calculating the difference between the max overlap border and the bottom infill")
 			}
 
 			internalInfill = append(internalInfill, parts...)
@@ -72,9 +93,8 @@ func (m internalInfillModifier) Modify(layers []data.PartitionedLayer) error {
 		if len(internalInfill) > 0 {
 			newLayer.attributes["infill"] = internalInfill
 		}
-	}
-
-	return nil
+		return newLayer, nil
+	})
 }
 
 func partDifference(part data.LayerPart, layerToRemove data.PartitionedLayer) ([]data.LayerPart, error) {
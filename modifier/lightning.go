@@ -0,0 +1,108 @@
+package modifier
+
+import (
+	"context"
+	"errors"
+	"github.com/aligator/goslice/clip"
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/handler"
+)
+
+type lightningInfillModifier struct {
+	handler.Named
+	options *data.Options
+}
+
+func (m lightningInfillModifier) Init(model data.OptimizedModel) {}
+
+// NewLightningInfillModifier replaces the internal infill by lightning infill if options.Print.InfillPattern is lightning.
+// It is meant to run after the internalInfillModifier and uses its "infill" attribute as the area
+// in which the trees may grow and the "top" attribute of the layer above as the area which needs support.
+// The lines are saved as the attribute "lightning" as data.Paths and the "infill" attribute is cleared.
+//
+// The layers are processed from the top to the bottom as the trees grow downwards.
+// The branches get shorter by the thickness of each layer, so they keep a slope of 45° also with adaptive layers.
+func NewLightningInfillModifier(options *data.Options) handler.LayerModifier {
+	return &lightningInfillModifier{
+		Named: handler.Named{
+			Name: "LightningInfill",
+		},
+		options: options,
+	}
+}
+
+// LightningInfill extracts the attribute "lightning" from the layer.
+// If it has the wrong type, a error is returned.
+// If it doesn't exist, (nil, nil) is returned.
+// If it exists, the lines are returned.
+func LightningInfill(layer data.PartitionedLayer) (data.Paths, error) {
+	if attr, ok := layer.Attributes()["lightning"]; ok {
+		lines, ok := attr.(data.Paths)
+		if !ok {
+			return nil, errors.New("the attribute lightning has the wrong datatype")
+		}
+
+		return lines, nil
+	}
+
+	return nil, nil
+}
+
+func (m lightningInfillModifier) Modify(ctx context.Context, layers []data.PartitionedLayer) error {
+	var lightning *clip.Lightning
+
+	for layerNr := len(layers) - 1; layerNr >= 0; layerNr-- {
+		if err := startLayer(ctx, m.options, m.GetName(), len(layers)-1-layerNr, len(layers)); err != nil {
+			return err
+		}
+
+		options := m.options.ForHeight(layers[layerNr].Z())
+
+		// Layers with another infill support everything above, so the trees start again below them.
+		if options.Print.InfillPattern != data.InfillPatternLightning || options.Print.InfillPercent == 0 {
+			lightning = nil
+			continue
+		}
+
+		if lightning == nil {
+			lightning = clip.NewLightning(options.Printer.ExtrusionWidth, options.InfillLineDistance())
+		}
+
+		area, err := PartsAttribute(layers[layerNr], "infill")
+		if err != nil {
+			return err
+		}
+
+		// The top layers above the infill need support.
+		var overhang []data.LayerPart
+		if layerNr < len(layers)-1 && len(area) > 0 {
+			top, err := TopInfill(layers[layerNr+1])
+			if err != nil {
+				return err
+			}
+
+			if len(top) > 0 {
+				var ok bool
+				overhang, ok = clip.NewClipper().Intersection(area, top)
+				if !ok {
+					return errors.New("error while intersecting the infill area with the top infill of the layer above")
+				}
+			}
+		}
+
+		lines, err := lightning.Layer(area, overhang, layers[layerNr].Thickness())
+		if err != nil {
+			return err
+		}
+
+		newLayer := newExtendedLayer(layers[layerNr])
+		// remove the infill of the internalInfillModifier
+		newLayer.attributes["infill"] = []data.LayerPart{}
+		if len(lines) > 0 {
+			newLayer.attributes["lightning"] = lines
+		}
+		layers[layerNr] = newLayer
+	}
+
+	return nil
+}
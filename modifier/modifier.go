@@ -1,8 +1,11 @@
 package modifier
 
 import (
+	"context"
 	"fmt"
 	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/util/parallel"
+	"sync"
 )
 
 // extendedLayer is a partitioned layer which supports types
@@ -55,3 +58,61 @@ func PartsAttribute(layer data.PartitionedLayer, typ string) ([]data.LayerPart,
 
 	return nil, nil
 }
+
+// startLayer has to be called by the modifiers before processing a layer.
+// It returns the error of the context if it is cancelled.
+// Otherwise it reports the progress using the amount of already finished layers.
+func startLayer(ctx context.Context, options *data.Options, name string, finished, layerCount int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	options.GoSlice.Progress.Report(data.Progress{
+		Stage:      data.StageModify,
+		Name:       name,
+		Layer:      finished,
+		LayerCount: layerCount,
+	})
+	return nil
+}
+
+// forEachLayer calls fn for the layers 0 to layerCount-1 concurrently using options.GoSlice.Concurrency.
+// fn gets the options of the layer with the height overrides applied (see data.Options.ForHeight).
+// fn must not change the layers slice but return the new layer which is written back
+// after all layers are done. So all layers always see the input of the modifier.
+// It returns the error of the context if it is cancelled and reports the progress after each finished layer.
+func forEachLayer(ctx context.Context, options *data.Options, name string, layers []data.PartitionedLayer, layerCount int, fn func(layerNr int, options *data.Options) (data.PartitionedLayer, error)) error {
+	if err := startLayer(ctx, options, name, 0, layerCount); err != nil {
+		return err
+	}
+
+	newLayers := make([]data.PartitionedLayer, layerCount)
+
+	var mutex sync.Mutex
+	finished := 0
+
+	err := parallel.ForEach(ctx, options.GoSlice.Concurrency, layerCount, func(layerNr int) error {
+		newLayer, err := fn(layerNr, options.ForHeight(layers[layerNr].Z()))
+		if err != nil {
+			return err
+		}
+		newLayers[layerNr] = newLayer
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		finished++
+		options.GoSlice.Progress.Report(data.Progress{
+			Stage:      data.StageModify,
+			Name:       name,
+			Layer:      finished,
+			LayerCount: layerCount,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	copy(layers, newLayers)
+	return nil
+}
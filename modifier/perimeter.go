@@ -1,6 +1,7 @@
 package modifier
 
 import (
+	"context"
 	"errors"
 	"github.com/aligator/goslice/clip"
 	"github.com/aligator/goslice/data"
@@ -60,11 +61,12 @@ func Perimeters(layer data.PartitionedLayer) (clip.OffsetResult, error) {
 
 func (m perimeterModifier) Init(_ data.OptimizedModel) {}
 
-func (m perimeterModifier) Modify(layers []data.PartitionedLayer) error {
-	for layerNr := range layers {
+func (m perimeterModifier) Modify(ctx context.Context, layers []data.PartitionedLayer) error {
+	// Each layer only depends on itself, so all layers can be processed concurrently.
+	return forEachLayer(ctx, m.options, m.GetName(), layers, len(layers), func(layerNr int, options *data.Options) (data.PartitionedLayer, error) {
 		// Generate the perimeters.
 		c := clip.NewClipper()
-		insetParts := c.InsetLayer(layers[layerNr].LayerParts(), m.options.Printer.ExtrusionWidth, m.options.Print.InsetCount, -m.options.Printer.ExtrusionWidth/2)
+		insetParts := c.InsetLayer(layers[layerNr].LayerParts(), options.Printer.ExtrusionWidth, options.Print.InsetCount, -options.Printer.ExtrusionWidth/2)
 
 		// Also generate the overlapping perimeter, which helps with calculating the infill.
 		// This is derived from the most inner perimeters and offset by the options.Print.InfillOverlapPercent option.
@@ -79,9 +81,9 @@ func (m perimeterModifier) Modify(layers []data.PartitionedLayer) error {
 			// Use only the most inner perimeter.
 			for _, insetPart := range part[len(part)-1] {
 
-				maxOverlapBorder, err := calculateOverlapPerimeter(insetPart, m.options.Print.InfillOverlapPercent, m.options.Printer.ExtrusionWidth)
+				maxOverlapBorder, err := calculateOverlapPerimeter(insetPart, options.Print.InfillOverlapPercent, options.Printer.ExtrusionWidth)
 				if err != nil {
-					return err
+					return nil, err
 				}
 				overlapPerimeter[partNr] = append(overlapPerimeter[partNr], maxOverlapBorder...)
 			}
@@ -90,10 +92,8 @@ func (m perimeterModifier) Modify(layers []data.PartitionedLayer) error {
 		newLayer := newExtendedLayer(layers[layerNr])
 		newLayer.attributes["perimeters"] = insetParts
 		newLayer.attributes["overlapPerimeters"] = overlapPerimeter
-		layers[layerNr] = newLayer
-	}
-
-	return nil
+		return newLayer, nil
+	})
 }
 
 // calculateOverlapPerimeter helper function for calculating the overlap-perimeter out of a layer part.
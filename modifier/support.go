@@ -5,6 +5,7 @@
 package modifier
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/aligator/goslice/clip"
@@ -71,8 +72,12 @@ func NewSupportDetectorModifier(options *data.Options) handler.LayerModifier {
 	}
 }
 
-func (m supportDetectorModifier) Modify(layers []data.PartitionedLayer) error {
+func (m supportDetectorModifier) Modify(ctx context.Context, layers []data.PartitionedLayer) error {
 	for layerNr := range layers {
+		if err := startLayer(ctx, m.options, m.GetName(), layerNr, len(layers)); err != nil {
+			return err
+		}
+
 		if !m.options.Print.Support.Enabled {
 			return nil
 		}
@@ -85,7 +90,7 @@ func (m supportDetectorModifier) Modify(layers []data.PartitionedLayer) error {
 		}
 
 		// calculate distance (d):
-		distance := float64(m.options.Print.LayerThickness) * math.Tan(data.ToRadians(float64(m.options.Print.Support.ThresholdAngle)))
+		distance := float64(layers[layerNr+1].Thickness()) * math.Tan(data.ToRadians(float64(m.options.Print.Support.ThresholdAngle)))
 
 		// offset layer by d
 		cl := clip.NewClipper()
@@ -131,11 +136,15 @@ func NewSupportGeneratorModifier(options *data.Options) handler.LayerModifier {
 	}
 }
 
-func (m supportGeneratorModifier) Modify(layers []data.PartitionedLayer) error {
+func (m supportGeneratorModifier) Modify(ctx context.Context, layers []data.PartitionedLayer) error {
 	var lastSupport []data.LayerPart = nil
 
 	// for each layer starting at the 2nd top layer (the top layer won't need support)
 	for layerNr := len(layers) - 2; layerNr >= 0; layerNr-- {
+		if err := startLayer(ctx, m.options, m.GetName(), len(layers)-2-layerNr, len(layers)-1); err != nil {
+			return err
+		}
+
 		if !m.options.Print.Support.Enabled || layerNr == 0 {
 			return nil
 		}
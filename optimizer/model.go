@@ -11,6 +11,7 @@ type optimizedModel struct {
 	points    []point
 	faces     []optimizedFace
 	modelSize data.MicroVec3
+	report    data.RepairReport
 }
 
 func (o optimizedModel) FaceCount() int {
@@ -73,6 +74,10 @@ func (o optimizedModel) Max() data.MicroVec3 {
 	return ret
 }
 
+func (o optimizedModel) RepairReport() data.RepairReport {
+	return o.report
+}
+
 func (o optimizedModel) getFaceIdxWithPoints(idx0, idx1, notFaceIdx int) int {
 	for _, faceIndex0 := range o.points[idx0].faceIndices {
 		if faceIndex0 == notFaceIdx {
@@ -91,32 +96,61 @@ func (o optimizedModel) getFaceIdxWithPoints(idx0, idx1, notFaceIdx int) int {
 }
 
 func (o optimizedModel) SaveDebugSTL(filename string) error {
-	triangles := make([]stl.Triangle, 0)
+	return SaveSTL(o, filename)
+}
 
-	for _, face := range o.faces {
-		triangles = append(triangles, stl.Triangle{
+func (o optimizedModel) Shells() []data.Model {
+	visited := make([]bool, len(o.faces))
+	var shells []data.Model
+
+	for start := range o.faces {
+		if visited[start] {
+			continue
+		}
+
+		// Collect all faces which can be reached through the touching faces.
+		visited[start] = true
+		shell := []int{start}
+		for i := 0; i < len(shell); i++ {
+			for _, touching := range o.faces[shell[i]].touching {
+				if touching != -1 && !visited[touching] {
+					visited[touching] = true
+					shell = append(shell, touching)
+				}
+			}
+		}
+
+		faces := make([]data.Face, len(shell))
+		for i, faceIndex := range shell {
+			points := o.faces[faceIndex].Points()
+			faces[i] = data.NewBasicFace([3]data.MicroVec3{points[0].Copy(), points[1].Copy(), points[2].Copy()})
+		}
+		shells = append(shells, data.NewBasicModel(faces))
+	}
+
+	return shells
+}
+
+// SaveSTL saves the model as binary stl file.
+func SaveSTL(m data.Model, filename string) error {
+	triangles := make([]stl.Triangle, 0, m.FaceCount())
+
+	for i := 0; i < m.FaceCount(); i++ {
+		points := m.Face(i).Points()
+		triangle := stl.Triangle{
 			Normal: [3]float32{
 				0, 0, 0,
 			},
-			Vertices: [3]stl.Vec3{
-				[3]float32{
-					float32(o.points[face.indices[0]].pos.X().ToMillimeter()),
-					float32(o.points[face.indices[0]].pos.Y().ToMillimeter()),
-					float32(o.points[face.indices[0]].pos.Z().ToMillimeter()),
-				},
-				[3]float32{
-					float32(o.points[face.indices[1]].pos.X().ToMillimeter()),
-					float32(o.points[face.indices[1]].pos.Y().ToMillimeter()),
-					float32(o.points[face.indices[1]].pos.Z().ToMillimeter()),
-				},
-				[3]float32{
-					float32(o.points[face.indices[2]].pos.X().ToMillimeter()),
-					float32(o.points[face.indices[2]].pos.Y().ToMillimeter()),
-					float32(o.points[face.indices[2]].pos.Z().ToMillimeter()),
-				},
-			},
 			Attributes: 0,
-		})
+		}
+		for k, p := range points {
+			triangle.Vertices[k] = [3]float32{
+				float32(p.X().ToMillimeter()),
+				float32(p.Y().ToMillimeter()),
+				float32(p.Z().ToMillimeter()),
+			}
+		}
+		triangles = append(triangles, triangle)
 	}
 
 	solid := stl.Solid{
@@ -16,7 +16,17 @@
 // 2. Removing duplicates:
 //    This is simply done by running through all faces and check if any faces have the same points.
 //
+// If options.Slicing.RepairMesh is enabled it also repairs the model:
+// 3. Removing degenerate faces:
+//    Faces which have all points on one line have no area and are just skipped.
+// 4. Fixing flipped faces:
+//    All faces which are connected through their touching faces get the same orientation.
+//    For each connected part the orientation of the biggest area is kept.
+// 5. Filling holes:
+//    The edges without a touching face are joined to loops which are closed by new faces.
+//
 // At the end the count of open faces is printed (faces which do not have a touching face on one side -> still existing error).
+// What was repaired is available as data.RepairReport.
 // Also the whole model is moved to the final place on the built plate.
 
 package optimizer
@@ -97,6 +107,16 @@ FacesLoop:
 		if optimizedFace.indices[0] == optimizedFace.indices[1] ||
 			optimizedFace.indices[0] == optimizedFace.indices[2] ||
 			optimizedFace.indices[1] == optimizedFace.indices[2] {
+			om.report.DegenerateFaces++
+			continue
+		}
+
+		if o.options.Slicing.RepairMesh && isDegenerate([3]data.MicroVec3{
+			om.points[optimizedFace.indices[0]].pos,
+			om.points[optimizedFace.indices[1]].pos,
+			om.points[optimizedFace.indices[2]].pos,
+		}) {
+			om.report.DegenerateFaces++
 			continue
 		}
 
@@ -107,6 +127,7 @@ FacesLoop:
 					if faceIndex0 == faceIndex1 &&
 						faceIndex0 == faceIndex2 {
 						// no need to go further
+						om.report.DuplicateFaces++
 						continue FacesLoop
 					}
 				}
@@ -122,37 +143,35 @@ FacesLoop:
 		om.faces = append(om.faces, optimizedFace)
 	}
 
-	// count open faces
-	openFaces := 0
-	for i, face := range om.faces {
-		face.touching = [3]int{
-			om.getFaceIdxWithPoints(face.indices[0], face.indices[1], i),
-			om.getFaceIdxWithPoints(face.indices[1], face.indices[2], i),
-			om.getFaceIdxWithPoints(face.indices[2], face.indices[0], i),
-		}
+	openFaces := om.calculateTouching()
 
-		if face.touching[0] == -1 {
-			openFaces++
+	if o.options.Slicing.RepairMesh {
+		// Close the holes left by removed degenerate faces first, as they would separate the touching faces.
+		if split := om.splitTJunctions(); split > 0 {
+			om.report.SplitFaces = split
+			om.calculateTouching()
 		}
 
-		if face.touching[1] == -1 {
-			openFaces++
-		}
-
-		if face.touching[2] == -1 {
-			openFaces++
-		}
+		om.report.FlippedFaces = om.fixOrientation()
 
-		om.faces[i] = face
+		holes, added := om.fillHoles()
+		om.report.FilledHoles += holes
+		om.report.AddedFaces += added
+		openFaces = om.calculateTouching()
 	}
+	om.report.OpenEdges = openFaces
 
 	o.options.GoSlice.Logger.Printf("Number of open faces: %v\n", openFaces)
+	if o.options.Slicing.RepairMesh {
+		o.options.GoSlice.Logger.Printf("Mesh repair: %v\n", om.report)
+	}
 
 	min := m.Min()
 	max := m.Max()
-	// move points according to the center value
+	// move points according to the center value and the additional translation
 	vectorOffset := data.NewMicroVec3((min.X()+max.X())/2, (min.Y()+max.Y())/2, min.Z())
 	vectorOffset = vectorOffset.Sub(o.options.Printer.Center)
+	vectorOffset = vectorOffset.Sub(data.NewMicroVec3(o.options.Transform.TranslateX.ToMicrometer(), o.options.Transform.TranslateY.ToMicrometer(), 0))
 	for i, point := range om.points {
 		om.points[i].pos = point.pos.Sub(vectorOffset)
 	}
@@ -0,0 +1,196 @@
+package optimizer_test
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/optimizer"
+	"github.com/aligator/goslice/reader"
+	"github.com/aligator/goslice/util/test"
+)
+
+// brokenCube returns a 10 mm cube without its top, with one flipped face and one face without area.
+func brokenCube() data.Model {
+	p := func(x, y, z data.Micrometer) data.MicroVec3 {
+		return data.NewMicroVec3(x*10000, y*10000, z*10000)
+	}
+	face := func(a, b, c data.MicroVec3) data.Face {
+		return data.NewBasicFace([3]data.MicroVec3{a, b, c})
+	}
+
+	return data.NewBasicModel([]data.Face{
+		// bottom
+		face(p(0, 0, 0), p(1, 1, 0), p(1, 0, 0)),
+		face(p(0, 0, 0), p(0, 1, 0), p(1, 1, 0)),
+		// front
+		face(p(0, 0, 0), p(1, 0, 0), p(1, 0, 1)),
+		face(p(0, 0, 0), p(1, 0, 1), p(0, 0, 1)),
+		// back, the second face is flipped
+		face(p(0, 1, 0), p(1, 1, 1), p(1, 1, 0)),
+		face(p(0, 1, 0), p(1, 1, 1), p(0, 1, 1)),
+		// left
+		face(p(0, 0, 0), p(0, 0, 1), p(0, 1, 1)),
+		face(p(0, 0, 0), p(0, 1, 1), p(0, 1, 0)),
+		// right
+		face(p(1, 0, 0), p(1, 1, 0), p(1, 1, 1)),
+		face(p(1, 0, 0), p(1, 1, 1), p(1, 0, 1)),
+		// without area
+		face(p(0, 0, 0), p(0, 0, 1), data.NewMicroVec3(0, 0, 20000)),
+	})
+}
+
+func TestRepair(t *testing.T) {
+	var tests = map[string]struct {
+		repair    bool
+		expected  data.RepairReport
+		faceCount int
+	}{
+		"repair": {
+			repair: true,
+			expected: data.RepairReport{
+				DegenerateFaces: 1,
+				FlippedFaces:    1,
+				FilledHoles:     1,
+				AddedFaces:      2,
+			},
+			faceCount: 12,
+		},
+		"no repair": {
+			repair: false,
+			expected: data.RepairReport{
+				OpenEdges: 6,
+			},
+			faceCount: 11,
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log("test case:", name)
+
+		options := data.DefaultOptions()
+		options.GoSlice.Logger = log.New(ioutil.Discard, "", 0)
+		options.Slicing.RepairMesh = testCase.repair
+
+		m, err := optimizer.NewOptimizer(&options).Optimize(brokenCube())
+		test.Ok(t, err)
+		test.Equals(t, testCase.expected, m.RepairReport())
+		test.Equals(t, testCase.faceCount, m.FaceCount())
+
+		if !testCase.repair {
+			continue
+		}
+
+		// Each edge has to be used in the opposite direction by the touching face.
+		for i := 0; i < m.FaceCount(); i++ {
+			face := m.OptimizedFace(i)
+			points := face.Points()
+			for k, touching := range face.TouchingFaceIndices() {
+				test.Assert(t, touching != -1, "face %v has no touching face at edge %v", i, k)
+
+				touchingPoints := m.OptimizedFace(touching).Points()
+				reversed := false
+				for j := 0; j < 3; j++ {
+					if touchingPoints[j] == points[(k+1)%3] && touchingPoints[(j+1)%3] == points[k] {
+						reversed = true
+					}
+				}
+				test.Assert(t, reversed, "face %v is not oriented like face %v", touching, i)
+			}
+		}
+	}
+}
+
+func TestRepairTJunction(t *testing.T) {
+	p := func(x, y, z data.Micrometer) data.MicroVec3 {
+		return data.NewMicroVec3(x*10000, y*10000, z*10000)
+	}
+	face := func(a, b, c data.MicroVec3) data.Face {
+		return data.NewBasicFace([3]data.MicroVec3{a, b, c})
+	}
+
+	// A closed 10 mm cube whose front uses a point in the middle of the bottom edge which is not used by the bottom.
+	middle := data.NewMicroVec3(5000, 0, 0)
+	model := data.NewBasicModel([]data.Face{
+		// bottom
+		face(p(0, 0, 0), p(1, 1, 0), p(1, 0, 0)),
+		face(p(0, 0, 0), p(0, 1, 0), p(1, 1, 0)),
+		// front
+		face(p(0, 0, 0), middle, p(0, 0, 1)),
+		face(middle, p(1, 0, 0), p(1, 0, 1)),
+		face(middle, p(1, 0, 1), p(0, 0, 1)),
+		// back
+		face(p(0, 1, 0), p(1, 1, 1), p(1, 1, 0)),
+		face(p(0, 1, 0), p(0, 1, 1), p(1, 1, 1)),
+		// left
+		face(p(0, 0, 0), p(0, 0, 1), p(0, 1, 1)),
+		face(p(0, 0, 0), p(0, 1, 1), p(0, 1, 0)),
+		// right
+		face(p(1, 0, 0), p(1, 1, 0), p(1, 1, 1)),
+		face(p(1, 0, 0), p(1, 1, 1), p(1, 0, 1)),
+		// top
+		face(p(0, 0, 1), p(1, 0, 1), p(1, 1, 1)),
+		face(p(0, 0, 1), p(1, 1, 1), p(0, 1, 1)),
+	})
+
+	options := data.DefaultOptions()
+	options.GoSlice.Logger = log.New(ioutil.Discard, "", 0)
+	options.Slicing.RepairMesh = true
+
+	m, err := optimizer.NewOptimizer(&options).Optimize(model)
+	test.Ok(t, err)
+
+	// Splitting the bottom face closes the gap without counting it as a hole.
+	test.Equals(t, data.RepairReport{SplitFaces: 1}, m.RepairReport())
+	test.Equals(t, 14, m.FaceCount())
+}
+
+func TestShells(t *testing.T) {
+	faces := test.CubeFaces(data.NewMicroVec3(0, 0, 0), 10000)
+	faces = append(faces, test.CubeFaces(data.NewMicroVec3(20000, 0, 0), 5000)...)
+
+	options := data.DefaultOptions()
+	options.GoSlice.Logger = log.New(ioutil.Discard, "", 0)
+
+	m, err := optimizer.NewOptimizer(&options).Optimize(data.NewBasicModel(faces))
+	test.Ok(t, err)
+
+	shells := m.Shells()
+	test.Equals(t, 2, len(shells))
+
+	// The shells are moved together with the model, so only their size is compared.
+	sizes := map[string]bool{}
+	for _, shell := range shells {
+		test.Equals(t, 12, shell.FaceCount())
+		sizes[shell.Max().Sub(shell.Min()).String()] = true
+	}
+	test.Equals(t, map[string]bool{
+		data.NewMicroVec3(10000, 10000, 10000).String(): true,
+		data.NewMicroVec3(5000, 5000, 5000).String():    true,
+	}, sizes)
+}
+
+func TestSaveSTL(t *testing.T) {
+	model := data.NewBasicModel(test.CubeFaces(data.NewMicroVec3(1000, 2000, 3000), 10000))
+
+	dir, err := ioutil.TempDir("", "goslice-optimizer")
+	test.Ok(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cube.stl")
+
+	test.Ok(t, optimizer.SaveSTL(model, path))
+
+	options := data.DefaultOptions()
+	saved, err := reader.STLReader(&options).Read(path)
+	test.Ok(t, err)
+
+	test.Equals(t, model.FaceCount(), saved.FaceCount())
+	for i := 0; i < model.FaceCount(); i++ {
+		for k, p := range model.Face(i).Points() {
+			test.Equals(t, p.String(), saved.Face(i).Points()[k].String())
+		}
+	}
+}
@@ -0,0 +1,342 @@
+// This file provides the repair steps of the optimizer.
+
+package optimizer
+
+import (
+	"math"
+
+	"github.com/aligator/goslice/data"
+)
+
+// isDegenerate checks if the three points are on one line, so that the face has no area.
+func isDegenerate(points [3]data.MicroVec3) bool {
+	a := points[1].Sub(points[0])
+	b := points[2].Sub(points[0])
+
+	// All components of the cross product have to be 0.
+	return a.Y()*b.Z() == a.Z()*b.Y() &&
+		a.Z()*b.X() == a.X()*b.Z() &&
+		a.X()*b.Y() == a.Y()*b.X()
+}
+
+// area calculates the area of the face.
+func (o optimizedFace) area() float64 {
+	points := o.Points()
+	ax, ay, az := float64(points[1].X()-points[0].X()), float64(points[1].Y()-points[0].Y()), float64(points[1].Z()-points[0].Z())
+	bx, by, bz := float64(points[2].X()-points[0].X()), float64(points[2].Y()-points[0].Y()), float64(points[2].Z()-points[0].Z())
+
+	return math.Sqrt(math.Pow(ay*bz-az*by, 2)+math.Pow(az*bx-ax*bz, 2)+math.Pow(ax*by-ay*bx, 2)) / 2
+}
+
+// hasEdge checks if the face contains the edge from the point index a to b in this direction.
+func (o optimizedFace) hasEdge(a, b int) bool {
+	for k := 0; k < 3; k++ {
+		if o.indices[k] == a && o.indices[(k+1)%3] == b {
+			return true
+		}
+	}
+	return false
+}
+
+// addFace adds a new face and registers it at its points.
+func (o *optimizedModel) addFace(indices [3]int) {
+	faceIndex := len(o.faces)
+	for _, index := range indices {
+		o.points[index].faceIndices = append(o.points[index].faceIndices, faceIndex)
+	}
+
+	o.faces = append(o.faces, optimizedFace{
+		indices:  indices,
+		touching: [3]int{-1, -1, -1},
+		model:    o,
+		index:    faceIndex,
+	})
+}
+
+// calculateTouching searches the touching face for each edge of each face.
+// The touching face of the edge from point k to point k+1 is saved at touching[k].
+// It returns the amount of edges without a touching face.
+func (o *optimizedModel) calculateTouching() int {
+	openEdges := 0
+	for i, face := range o.faces {
+		face.touching = [3]int{
+			o.getFaceIdxWithPoints(face.indices[0], face.indices[1], i),
+			o.getFaceIdxWithPoints(face.indices[1], face.indices[2], i),
+			o.getFaceIdxWithPoints(face.indices[2], face.indices[0], i),
+		}
+
+		for _, touching := range face.touching {
+			if touching == -1 {
+				openEdges++
+			}
+		}
+
+		o.faces[i] = face
+	}
+
+	return openEdges
+}
+
+// flip reverses the orientation of the face.
+func (o *optimizedModel) flip(faceIndex int) {
+	face := &o.faces[faceIndex]
+	face.indices[1], face.indices[2] = face.indices[2], face.indices[1]
+	// The edges are now (0, 2), (2, 1) and (1, 0) which were the edges 2, 1 and 0 before.
+	face.touching = [3]int{face.touching[2], face.touching[1], face.touching[0]}
+}
+
+// fixOrientation makes the orientation of all touching faces consistent,
+// so that each edge is used in both directions by the two faces sharing it.
+// For each connected part of the model, the orientation of the faces with the bigger area is kept.
+// It returns the amount of flipped faces.
+func (o *optimizedModel) fixOrientation() int {
+	visited := make([]bool, len(o.faces))
+	flipped := make([]bool, len(o.faces))
+	flippedCount := 0
+
+	for start := range o.faces {
+		if visited[start] {
+			continue
+		}
+
+		// Walk through all connected faces and flip each one which does not match the face it was reached from.
+		visited[start] = true
+		component := []int{start}
+		for i := 0; i < len(component); i++ {
+			face := o.faces[component[i]]
+			for k, touching := range face.touching {
+				if touching == -1 || visited[touching] {
+					continue
+				}
+				visited[touching] = true
+
+				// The touching face has to use the edge in the opposite direction.
+				if o.faces[touching].hasEdge(face.indices[k], face.indices[(k+1)%3]) {
+					o.flip(touching)
+					flipped[touching] = true
+				}
+				component = append(component, touching)
+			}
+		}
+
+		var flippedArea, keptArea float64
+		for _, faceIndex := range component {
+			if flipped[faceIndex] {
+				flippedArea += o.faces[faceIndex].area()
+			} else {
+				keptArea += o.faces[faceIndex].area()
+			}
+		}
+
+		// If most of the part was flipped, the start face was the wrong one, so flip the whole part back.
+		for _, faceIndex := range component {
+			if flippedArea > keptArea {
+				o.flip(faceIndex)
+				flipped[faceIndex] = !flipped[faceIndex]
+			}
+			if flipped[faceIndex] {
+				flippedCount++
+			}
+		}
+	}
+
+	return flippedCount
+}
+
+// removeFaceIndex removes the face from the faces of the point.
+func (o *optimizedModel) removeFaceIndex(pointIndex, faceIndex int) {
+	faceIndices := o.points[pointIndex].faceIndices
+	for i, index := range faceIndices {
+		if index == faceIndex {
+			o.points[pointIndex].faceIndices = append(faceIndices[:i], faceIndices[i+1:]...)
+			return
+		}
+	}
+}
+
+// splitTJunctions closes the holes without area which are left by removed degenerate faces.
+// Such a hole consists of an open edge from p to q and a point r exactly between them,
+// which is connected to p and q by open edges. The face with the edge from p to q is split at r.
+// Touching faces have to be calculated again afterwards.
+// It returns the amount of split faces.
+func (o *optimizedModel) splitTJunctions() int {
+	openNeighbours := map[int][]int{}
+	for _, face := range o.faces {
+		for k, touching := range face.touching {
+			if touching == -1 {
+				p, q := face.indices[k], face.indices[(k+1)%3]
+				openNeighbours[p] = append(openNeighbours[p], q)
+				openNeighbours[q] = append(openNeighbours[q], p)
+			}
+		}
+	}
+
+	isNeighbour := func(a, b int) bool {
+		for _, n := range openNeighbours[a] {
+			if n == b {
+				return true
+			}
+		}
+		return false
+	}
+
+	split := 0
+	faceCount := len(o.faces)
+	for i := 0; i < faceCount; i++ {
+		for k := 0; k < 3; k++ {
+			face := o.faces[i]
+			if face.touching[k] != -1 {
+				continue
+			}
+
+			p, q, x := face.indices[k], face.indices[(k+1)%3], face.indices[(k+2)%3]
+			for _, r := range openNeighbours[p] {
+				if r == q || r == x || !isNeighbour(r, q) || !o.isBetween(r, p, q) {
+					continue
+				}
+
+				// Replace the face (p, q, x) by (p, r, x) and (r, q, x).
+				o.faces[i].indices = [3]int{p, r, x}
+				o.faces[i].touching = [3]int{-1, len(o.faces), face.touching[(k+2)%3]}
+				o.removeFaceIndex(q, i)
+				o.points[r].faceIndices = append(o.points[r].faceIndices, i)
+				o.addFace([3]int{r, q, x})
+
+				split++
+				break
+			}
+		}
+	}
+
+	return split
+}
+
+// isBetween checks if the point r is on the line segment from p to q.
+func (o *optimizedModel) isBetween(r, p, q int) bool {
+	pPos, qPos, rPos := o.points[p].pos, o.points[q].pos, o.points[r].pos
+	if !isDegenerate([3]data.MicroVec3{pPos, qPos, rPos}) {
+		return false
+	}
+
+	pq := qPos.Sub(pPos)
+	pr := rPos.Sub(pPos)
+	dot := pq.X()*pr.X() + pq.Y()*pr.Y() + pq.Z()*pr.Z()
+	return dot > 0 && dot < pq.X()*pq.X()+pq.Y()*pq.Y()+pq.Z()*pq.Z()
+}
+
+// boundaryEdge is an edge of a hole.
+type boundaryEdge struct {
+	to   int
+	used bool
+}
+
+// fillHoles searches all closed loops of edges without touching faces and fills them with new faces.
+// It returns the amount of filled holes and the amount of added faces.
+func (o *optimizedModel) fillHoles() (holes int, addedFaces int) {
+	// The new faces have to use the open edges in the opposite direction to have the same orientation.
+	edges := map[int][]*boundaryEdge{}
+	var starts []int
+	for _, face := range o.faces {
+		for k, touching := range face.touching {
+			if touching != -1 {
+				continue
+			}
+			from := face.indices[(k+1)%3]
+			edges[from] = append(edges[from], &boundaryEdge{to: face.indices[k]})
+			starts = append(starts, from)
+		}
+	}
+
+	nextEdge := func(from int) *boundaryEdge {
+		for _, edge := range edges[from] {
+			if !edge.used {
+				return edge
+			}
+		}
+		return nil
+	}
+
+	for _, start := range starts {
+		edge := nextEdge(start)
+		if edge == nil {
+			continue
+		}
+
+		// Follow the edges until the loop is closed.
+		loop := []int{start}
+		closed := false
+		for edge != nil {
+			edge.used = true
+			if edge.to == start {
+				closed = true
+				break
+			}
+			loop = append(loop, edge.to)
+			edge = nextEdge(edge.to)
+		}
+
+		if !closed || len(loop) < 3 {
+			continue
+		}
+
+		added := o.fillLoop(loop)
+		if added > 0 {
+			holes++
+			addedFaces += added
+		}
+	}
+
+	return holes, addedFaces
+}
+
+// fillLoop triangulates the loop of point indices.
+// It always cuts off the point with the smallest angle between its neighbours.
+// This works for holes which are roughly planar and is good enough for the usual small holes.
+// Faces without area are not added.
+// It returns the amount of added faces.
+func (o *optimizedModel) fillLoop(loop []int) int {
+	angle := func(i int) float64 {
+		prev := o.points[loop[(i+len(loop)-1)%len(loop)]].pos
+		current := o.points[loop[i]].pos
+		next := o.points[loop[(i+1)%len(loop)]].pos
+
+		a := prev.Sub(current)
+		b := next.Sub(current)
+		ax, ay, az := float64(a.X()), float64(a.Y()), float64(a.Z())
+		bx, by, bz := float64(b.X()), float64(b.Y()), float64(b.Z())
+
+		lengths := math.Sqrt(ax*ax+ay*ay+az*az) * math.Sqrt(bx*bx+by*by+bz*bz)
+		if lengths == 0 {
+			return 0
+		}
+		return math.Acos(math.Max(-1, math.Min(1, (ax*bx+ay*by+az*bz)/lengths)))
+	}
+
+	added := 0
+	for len(loop) >= 3 {
+		smallest := 0
+		if len(loop) > 3 {
+			smallestAngle := math.Inf(1)
+			for i := range loop {
+				if a := angle(i); a < smallestAngle {
+					smallest = i
+					smallestAngle = a
+				}
+			}
+		}
+
+		indices := [3]int{
+			loop[(smallest+len(loop)-1)%len(loop)],
+			loop[smallest],
+			loop[(smallest+1)%len(loop)],
+		}
+		if indices[0] != indices[2] && !isDegenerate([3]data.MicroVec3{o.points[indices[0]].pos, o.points[indices[1]].pos, o.points[indices[2]].pos}) {
+			o.addFace(indices)
+			added++
+		}
+
+		loop = append(loop[:smallest], loop[smallest+1:]...)
+	}
+
+	return added
+}
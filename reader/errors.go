@@ -0,0 +1,54 @@
+// This file provides the errors returned by the readers if a model file is invalid.
+// They can be checked using errors.Is or errors.As to reject bad files.
+
+package reader
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrEmptyModel is returned if the model file does not contain any face.
+var ErrEmptyModel = errors.New("the model does not contain any faces")
+
+// TruncatedError is returned if a binary STL file ends
+// before all triangles announced by its header could be read.
+type TruncatedError struct {
+	// TriangleIndex is the index of the first incomplete triangle.
+	// It is -1 if even the header is incomplete.
+	TriangleIndex int
+}
+
+func (e *TruncatedError) Error() string {
+	if e.TriangleIndex < 0 {
+		return "the binary stl header is incomplete"
+	}
+	return fmt.Sprintf("the binary stl file is truncated at triangle %d", e.TriangleIndex)
+}
+
+// TriangleCountError is returned if the triangle count in the header of a binary STL file
+// does not match the amount of triangles which are actually contained in the file.
+type TriangleCountError struct {
+	// Expected is the triangle count from the header.
+	Expected int
+
+	// Actual is the triangle count calculated from the file size.
+	Actual int
+}
+
+func (e *TriangleCountError) Error() string {
+	return fmt.Sprintf("the binary stl header announces %d triangles but the file contains %d", e.Expected, e.Actual)
+}
+
+// InvalidVertexError is returned if a vertex has a coordinate which is NaN or infinite.
+type InvalidVertexError struct {
+	// TriangleIndex is the index of the triangle containing the vertex.
+	TriangleIndex int
+
+	// VertexIndex is the index (0 to 2) of the vertex inside of the triangle.
+	VertexIndex int
+}
+
+func (e *InvalidVertexError) Error() string {
+	return fmt.Sprintf("vertex %d of triangle %d has an invalid coordinate (NaN or infinite)", e.VertexIndex, e.TriangleIndex)
+}
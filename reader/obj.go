@@ -0,0 +1,148 @@
+// This file provides a reader for Wavefront OBJ files.
+//
+// Only the geometry is used: all vertices ("v") and faces ("f") of all groups and objects
+// are merged into one model. Faces with more than three vertices are triangulated as a fan.
+// Texture coordinates, normals, materials and so on are ignored.
+//
+// See http://paulbourke.net/dataformats/obj/ for the format.
+
+package reader
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/handler"
+)
+
+type objReader struct{}
+
+// OBJReader returns a Wavefront OBJ model reader which also implements handler.ModelStreamReader.
+// The values of the file are interpreted as millimeter.
+func OBJReader(options *data.Options) handler.ModelReader {
+	return &objReader{}
+}
+
+func (r objReader) Read(filename string) (data.Model, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	return readOBJ(file)
+}
+
+func (r objReader) ReadStream(in io.Reader) (data.Model, error) {
+	return readOBJ(in)
+}
+
+// readOBJ reads all faces of the OBJ data.
+func readOBJ(r io.Reader) (data.Model, error) {
+	model := &Model{}
+	var vertices [][3]float64
+
+	scanner := bufio.NewScanner(r)
+	lineNr := 0
+	for scanner.Scan() {
+		lineNr++
+
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "v":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("obj: line %d: a vertex needs at least three coordinates", lineNr)
+			}
+
+			var vertex [3]float64
+			for i := range vertex {
+				value, err := strconv.ParseFloat(fields[i+1], 64)
+				if err != nil {
+					return nil, fmt.Errorf("obj: line %d: invalid vertex coordinate: %w", lineNr, err)
+				}
+				vertex[i] = value
+			}
+			vertices = append(vertices, vertex)
+		case "f":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("obj: line %d: a face needs at least three vertices", lineNr)
+			}
+
+			indices := make([]int, len(fields)-1)
+			for i, field := range fields[1:] {
+				index, err := objVertexIndex(field, len(vertices))
+				if err != nil {
+					return nil, fmt.Errorf("obj: line %d: %w", lineNr, err)
+				}
+				indices[i] = index
+			}
+
+			// Triangulate polygons as a fan around the first vertex.
+			for i := 1; i < len(indices)-1; i++ {
+				f, err := newFace(len(model.faces), [3][3]float64{
+					vertices[indices[0]],
+					vertices[indices[i]],
+					vertices[indices[i+1]],
+				})
+				if err != nil {
+					return nil, fmt.Errorf("obj: line %d: %w", lineNr, err)
+				}
+				model.faces = append(model.faces, f)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("obj: %w", err)
+	}
+
+	if len(model.faces) == 0 {
+		return nil, ErrEmptyModel
+	}
+
+	return model, nil
+}
+
+// objVertexIndex converts a vertex reference of a face (e.g. "3", "3/1", "3//2" or "-1")
+// to the zero based index of the vertex.
+// Negative indices are relative to the vertices read so far.
+func objVertexIndex(reference string, vertexCount int) (int, error) {
+	if i := strings.IndexByte(reference, '/'); i >= 0 {
+		reference = reference[:i]
+	}
+
+	index, err := strconv.Atoi(reference)
+	if err != nil {
+		return 0, fmt.Errorf("invalid vertex reference %q", reference)
+	}
+
+	if index < 0 {
+		index = vertexCount + index
+	} else {
+		index--
+	}
+
+	if index < 0 || index >= vertexCount {
+		return 0, fmt.Errorf("the vertex reference %q does not exist", reference)
+	}
+
+	return index, nil
+}
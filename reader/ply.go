@@ -0,0 +1,329 @@
+// This file provides a reader for PLY (Polygon File Format) files.
+//
+// It supports the ascii format as well as the binary little and big endian formats.
+// Only the x, y and z properties of the vertices and the vertex indices of the faces are used.
+// All other elements and properties are skipped.
+// Faces with more than three vertices are triangulated as a fan.
+//
+// See http://paulbourke.net/dataformats/ply/ for the format.
+
+package reader
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/handler"
+)
+
+// plyTypeSizes contains the size in bytes of all scalar types allowed in PLY files.
+var plyTypeSizes = map[string]int{
+	"char":    1,
+	"int8":    1,
+	"uchar":   1,
+	"uint8":   1,
+	"short":   2,
+	"int16":   2,
+	"ushort":  2,
+	"uint16":  2,
+	"int":     4,
+	"int32":   4,
+	"uint":    4,
+	"uint32":  4,
+	"float":   4,
+	"float32": 4,
+	"double":  8,
+	"float64": 8,
+}
+
+type plyProperty struct {
+	name string
+	typ  string
+
+	// isList is true for list properties. countType is then the type of the list length.
+	isList    bool
+	countType string
+}
+
+type plyElement struct {
+	name       string
+	count      int
+	properties []plyProperty
+}
+
+// plyValueReader reads single values of a PLY file body.
+type plyValueReader interface {
+	read(typ string) (float64, error)
+}
+
+// plyASCIIReader reads values which are separated by whitespace.
+type plyASCIIReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r plyASCIIReader) read(typ string) (float64, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return 0, err
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	return strconv.ParseFloat(r.scanner.Text(), 64)
+}
+
+// plyBinaryReader reads binary values using the given byte order.
+type plyBinaryReader struct {
+	r     io.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+func (r *plyBinaryReader) read(typ string) (float64, error) {
+	size := plyTypeSizes[typ]
+	buf := r.buf[:size]
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		if err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+
+	switch typ {
+	case "char", "int8":
+		return float64(int8(buf[0])), nil
+	case "uchar", "uint8":
+		return float64(buf[0]), nil
+	case "short", "int16":
+		return float64(int16(r.order.Uint16(buf))), nil
+	case "ushort", "uint16":
+		return float64(r.order.Uint16(buf)), nil
+	case "int", "int32":
+		return float64(int32(r.order.Uint32(buf))), nil
+	case "uint", "uint32":
+		return float64(r.order.Uint32(buf)), nil
+	case "float", "float32":
+		return float64(math.Float32frombits(r.order.Uint32(buf))), nil
+	default:
+		return math.Float64frombits(r.order.Uint64(buf)), nil
+	}
+}
+
+type plyReader struct{}
+
+// PLYReader returns a PLY model reader which also implements handler.ModelStreamReader.
+// The values of the file are interpreted as millimeter.
+func PLYReader(options *data.Options) handler.ModelReader {
+	return &plyReader{}
+}
+
+func (r plyReader) Read(filename string) (data.Model, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	return readPLY(file)
+}
+
+func (r plyReader) ReadStream(in io.Reader) (data.Model, error) {
+	return readPLY(in)
+}
+
+// readPLY reads all faces of the PLY data.
+func readPLY(r io.Reader) (data.Model, error) {
+	br := bufio.NewReader(r)
+
+	format, elements, err := readPLYHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	var values plyValueReader
+	switch format {
+	case "ascii":
+		scanner := bufio.NewScanner(br)
+		scanner.Split(bufio.ScanWords)
+		values = plyASCIIReader{scanner: scanner}
+	case "binary_little_endian":
+		values = &plyBinaryReader{r: br, order: binary.LittleEndian}
+	case "binary_big_endian":
+		values = &plyBinaryReader{r: br, order: binary.BigEndian}
+	default:
+		return nil, fmt.Errorf("ply: unknown format %s", format)
+	}
+
+	model := &Model{}
+	var vertices [][3]float64
+	var faces [][]int
+
+	for _, element := range elements {
+		for i := 0; i < element.count; i++ {
+			var vertex [3]float64
+			var indices []int
+
+			for _, property := range element.properties {
+				if !property.isList {
+					value, err := values.read(property.typ)
+					if err != nil {
+						return nil, fmt.Errorf("ply: could not read %s %d: %w", element.name, i, err)
+					}
+
+					if element.name == "vertex" {
+						switch property.name {
+						case "x":
+							vertex[0] = value
+						case "y":
+							vertex[1] = value
+						case "z":
+							vertex[2] = value
+						}
+					}
+					continue
+				}
+
+				count, err := values.read(property.countType)
+				if err != nil {
+					return nil, fmt.Errorf("ply: could not read %s %d: %w", element.name, i, err)
+				}
+
+				isIndexList := element.name == "face" && (property.name == "vertex_indices" || property.name == "vertex_index")
+				for j := 0; j < int(count); j++ {
+					value, err := values.read(property.typ)
+					if err != nil {
+						return nil, fmt.Errorf("ply: could not read %s %d: %w", element.name, i, err)
+					}
+
+					if isIndexList {
+						indices = append(indices, int(value))
+					}
+				}
+			}
+
+			switch element.name {
+			case "vertex":
+				vertices = append(vertices, vertex)
+			case "face":
+				faces = append(faces, indices)
+			}
+		}
+	}
+
+	// The faces are built at the end because the vertices may be declared after them.
+	for i, indices := range faces {
+		for _, index := range indices {
+			if index < 0 || index >= len(vertices) {
+				return nil, fmt.Errorf("ply: face %d references the unknown vertex %d", i, index)
+			}
+		}
+
+		// Triangulate polygons as a fan around the first vertex.
+		for j := 1; j < len(indices)-1; j++ {
+			f, err := newFace(len(model.faces), [3][3]float64{
+				vertices[indices[0]],
+				vertices[indices[j]],
+				vertices[indices[j+1]],
+			})
+			if err != nil {
+				return nil, fmt.Errorf("ply: face %d: %w", i, err)
+			}
+			model.faces = append(model.faces, f)
+		}
+	}
+
+	if len(model.faces) == 0 {
+		return nil, ErrEmptyModel
+	}
+
+	return model, nil
+}
+
+// readPLYHeader reads the header including the "end_header" line.
+// It returns the format and all declared elements in the order of the file.
+func readPLYHeader(r *bufio.Reader) (format string, elements []plyElement, err error) {
+	magic, err := r.ReadString('\n')
+	if err != nil || strings.TrimSpace(magic) != "ply" {
+		return "", nil, errors.New("ply: missing ply header")
+	}
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", nil, errors.New("ply: incomplete header")
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "format":
+			if len(fields) != 3 {
+				return "", nil, fmt.Errorf("ply: invalid format line %q", strings.TrimSpace(line))
+			}
+			format = fields[1]
+		case "element":
+			if len(fields) != 3 {
+				return "", nil, fmt.Errorf("ply: invalid element line %q", strings.TrimSpace(line))
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return "", nil, fmt.Errorf("ply: invalid element count in line %q", strings.TrimSpace(line))
+			}
+			elements = append(elements, plyElement{
+				name:  fields[1],
+				count: count,
+			})
+		case "property":
+			if len(elements) == 0 {
+				return "", nil, errors.New("ply: property declared before any element")
+			}
+
+			var property plyProperty
+			switch {
+			case len(fields) == 5 && fields[1] == "list":
+				property = plyProperty{
+					name:      fields[4],
+					typ:       fields[3],
+					isList:    true,
+					countType: fields[2],
+				}
+			case len(fields) == 3:
+				property = plyProperty{
+					name: fields[2],
+					typ:  fields[1],
+				}
+			default:
+				return "", nil, fmt.Errorf("ply: invalid property line %q", strings.TrimSpace(line))
+			}
+
+			if _, ok := plyTypeSizes[property.typ]; !ok {
+				return "", nil, fmt.Errorf("ply: unknown type %s", property.typ)
+			}
+			if _, ok := plyTypeSizes[property.countType]; property.isList && !ok {
+				return "", nil, fmt.Errorf("ply: unknown type %s", property.countType)
+			}
+
+			last := &elements[len(elements)-1]
+			last.properties = append(last.properties, property)
+		case "end_header":
+			if format == "" {
+				return "", nil, errors.New("ply: missing format")
+			}
+			return format, elements, nil
+		}
+	}
+}
@@ -1,8 +1,18 @@
+// Package reader provides the built in model readers.
+// It supports STL, 3MF, OBJ and PLY files.
+// The format is detected by the file extension or, if the extension is unknown, by the content of the file.
+
 package reader
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/aligator/goslice/data"
 	"github.com/aligator/goslice/handler"
@@ -61,6 +71,10 @@ func (m Model) Face(index int) data.Face {
 }
 
 func (m Model) Min() data.MicroVec3 {
+	if len(m.faces) == 0 {
+		return data.NewMicroVec3(0, 0, 0)
+	}
+
 	ret := m.faces[0].Points()[0].Copy()
 
 	for _, face := range m.faces {
@@ -83,6 +97,10 @@ func (m Model) Min() data.MicroVec3 {
 }
 
 func (m Model) Max() data.MicroVec3 {
+	if len(m.faces) == 0 {
+		return data.NewMicroVec3(0, 0, 0)
+	}
+
 	ret := m.faces[0].Points()[0].Copy()
 
 	for _, face := range m.faces {
@@ -104,37 +122,195 @@ func (m Model) Max() data.MicroVec3 {
 	return ret
 }
 
-type reader struct{}
+// newFace creates a face out of three points given in millimeter.
+// An *InvalidVertexError is returned if a coordinate is NaN or infinite.
+// The index is only used for this error.
+func newFace(index int, points [3][3]float64) (face, error) {
+	var f face
+	for i, p := range points {
+		for _, v := range p {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return f, &InvalidVertexError{TriangleIndex: index, VertexIndex: i}
+			}
+		}
+
+		f.vectors[i] = data.NewMicroVec3(
+			data.Millimeter(p[0]).ToMicrometer(),
+			data.Millimeter(p[1]).ToMicrometer(),
+			data.Millimeter(p[2]).ToMicrometer(),
+		)
+	}
+	return f, nil
+}
+
+// zipSignature is the magic number at the beginning of each zip archive.
+// 3MF files are zip archives.
+var zipSignature = []byte("PK\x03\x04")
+
+// plySignature is the magic number at the beginning of each PLY file.
+var plySignature = []byte("ply")
+
+// objKeywords are the statements an OBJ file usually starts with (after comments).
+var objKeywords = map[string]bool{
+	"v":      true,
+	"vt":     true,
+	"vn":     true,
+	"f":      true,
+	"o":      true,
+	"g":      true,
+	"s":      true,
+	"mtllib": true,
+	"usemtl": true,
+}
+
+// formatDetectionSize is the amount of bytes needed to detect the format by the content.
+const formatDetectionSize = stlASCIIDetectionSize
+
+type reader struct {
+	options *data.Options
+	stl     handler.ModelReader
+	threeMF handler.ModelReader
+	obj     handler.ModelReader
+	ply     handler.ModelReader
+}
 
-// Reader returns a stl model reader.
+// Reader returns a model reader which supports all formats known by GoSlice.
+// It chooses the format by the file extension.
+// If the extension is not known, the format is detected by the content of the file.
+// It also implements handler.ModelStreamReader which detects the format by the content
+// unless the stream is a file with a known extension.
 func Reader(options *data.Options) handler.ModelReader {
-	return &reader{}
+	return &reader{
+		options: options,
+		stl:     STLReader(options),
+		threeMF: ThreeMFReader(options),
+		obj:     OBJReader(options),
+		ply:     PLYReader(options),
+	}
 }
 
 func (r reader) Read(filename string) (data.Model, error) {
-	model := &Model{}
-	if _, err := os.Stat(filename); errors.Is(err, os.ErrNotExist) {
-		return model, os.ErrNotExist
-	}
-	stl.CopyFile(filename, model)
-	return model, nil
-}
-
-// stlTriangleToFace converts a triangle from the stl package
-// into a face.
-func stlTriangleToFace(t stl.Triangle) face {
-	return face{vectors: [3]data.MicroVec3{
-		data.NewMicroVec3(
-			data.Millimeter(t.Vertices[0][0]).ToMicrometer(),
-			data.Millimeter(t.Vertices[0][1]).ToMicrometer(),
-			data.Millimeter(t.Vertices[0][2]).ToMicrometer()),
-		data.NewMicroVec3(
-			data.Millimeter(t.Vertices[1][0]).ToMicrometer(),
-			data.Millimeter(t.Vertices[1][1]).ToMicrometer(),
-			data.Millimeter(t.Vertices[1][2]).ToMicrometer()),
-		data.NewMicroVec3(
-			data.Millimeter(t.Vertices[2][0]).ToMicrometer(),
-			data.Millimeter(t.Vertices[2][1]).ToMicrometer(),
-			data.Millimeter(t.Vertices[2][2]).ToMicrometer()),
-	}}
+	if byExtension := r.byExtension(filename); byExtension != nil {
+		return byExtension.Read(filename)
+	}
+
+	// Unknown extension -> sniff the content.
+	file, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	head := make([]byte, formatDetectionSize)
+	n, err := io.ReadFull(file, head)
+	_ = file.Close()
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+
+	return r.byContent(head[:n], info.Size()).Read(filename)
+}
+
+func (r reader) ReadStream(in io.Reader) (data.Model, error) {
+	// Files are handled like in Read, so the extension is preferred over the content.
+	file, isFile := in.(*os.File)
+	if isFile {
+		if byExtension := r.byExtension(file.Name()); byExtension != nil {
+			streamReader, ok := byExtension.(handler.ModelStreamReader)
+			if !ok {
+				return nil, errors.New("the model reader of the file extension does not support streams")
+			}
+			return streamReader.ReadStream(file)
+		}
+	}
+
+	head := make([]byte, formatDetectionSize)
+	n, err := io.ReadFull(in, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	// The size is only known if the stream is already complete or a file.
+	// It is only needed to distinguish binary and ascii STL files.
+	size := int64(-1)
+	if n < formatDetectionSize {
+		size = int64(n)
+	} else if isFile {
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+		size = info.Size()
+	}
+
+	streamReader, ok := r.byContent(head, size).(handler.ModelStreamReader)
+	if !ok {
+		return nil, errors.New("the detected model reader does not support streams")
+	}
+
+	return streamReader.ReadStream(io.MultiReader(bytes.NewReader(head), in))
+}
+
+// byExtension chooses the reader by the extension of the file name.
+// It returns nil if the extension is not known.
+func (r reader) byExtension(filename string) handler.ModelReader {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".stl":
+		return r.stl
+	case ".3mf":
+		return r.threeMF
+	case ".obj":
+		return r.obj
+	case ".ply":
+		return r.ply
+	}
+
+	return nil
+}
+
+// byContent chooses the reader by the beginning of the file.
+// STL is used if no other format matches.
+// size is the size of the whole file or -1 if it is not known.
+func (r reader) byContent(head []byte, size int64) handler.ModelReader {
+	switch {
+	case bytes.HasPrefix(head, zipSignature):
+		return r.threeMF
+	case bytes.HasPrefix(head, plySignature):
+		return r.ply
+	case isOBJ(head, size):
+		return r.obj
+	default:
+		return r.stl
+	}
+}
+
+// isOBJ checks if the first statement of the file is a known OBJ statement.
+// Files which have the exact size of a binary STL are never detected as OBJ.
+func isOBJ(head []byte, size int64) bool {
+	if len(head) >= stlBinaryHeaderSize {
+		count := int64(binary.LittleEndian.Uint32(head[80:stlBinaryHeaderSize]))
+		if size == stlBinaryHeaderSize+count*stlBinaryTriangleSize {
+			return false
+		}
+	}
+
+	for _, line := range strings.Split(string(head), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		return objKeywords[fields[0]]
+	}
+
+	return false
 }
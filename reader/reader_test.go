@@ -0,0 +1,542 @@
+package reader_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/handler"
+	"github.com/aligator/goslice/reader"
+	"github.com/aligator/goslice/util/test"
+)
+
+// tetrahedron3MF is a tetrahedron with a size of 1 x 1 x 1 in the unit of the model.
+// It is placed twice: once using an object directly and once through a component.
+const tetrahedron3MF = `<?xml version="1.0" encoding="UTF-8"?>
+<model unit="%s" xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02">
+  <resources>
+    <object id="1" type="model">
+      <mesh>
+        <vertices>
+          <vertex x="0" y="0" z="0" />
+          <vertex x="1" y="0" z="0" />
+          <vertex x="0" y="1" z="0" />
+          <vertex x="0" y="0" z="1" />
+        </vertices>
+        <triangles>
+          <triangle v1="0" v2="2" v3="1" />
+          <triangle v1="0" v2="1" v3="3" />
+          <triangle v1="1" v2="2" v3="3" />
+          <triangle v1="0" v2="3" v3="2" />
+        </triangles>
+      </mesh>
+    </object>
+    <object id="2" type="model">
+      <components>
+        <component objectid="1" transform="1 0 0 0 1 0 0 0 1 5 0 0" />
+      </components>
+    </object>
+  </resources>
+  <build>
+    <item objectid="1" />
+    <item objectid="2" transform="2 0 0 0 2 0 0 0 2 0 10 0" />
+  </build>
+</model>`
+
+const rels3MF = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Target="/3D/3dmodel.model" Id="rel0" Type="http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel" />
+</Relationships>`
+
+// writeTestFile creates a file with the given content in a new temporary directory.
+// The returned function removes it again.
+func writeTestFile(t *testing.T, name string, content []byte) (string, func()) {
+	dir, err := ioutil.TempDir("", "goslice-reader")
+	test.Ok(t, err)
+
+	path := filepath.Join(dir, name)
+	test.Ok(t, ioutil.WriteFile(path, content, 0644))
+
+	return path, func() {
+		_ = os.RemoveAll(dir)
+	}
+}
+
+// write3MF creates a 3MF archive containing the given files.
+func write3MF(t *testing.T, name string, files map[string]string) (string, func()) {
+	path, cleanup := writeTestFile(t, name, nil)
+
+	file, err := os.Create(path)
+	test.Ok(t, err)
+
+	w := zip.NewWriter(file)
+	for fileName, content := range files {
+		f, err := w.Create(fileName)
+		test.Ok(t, err)
+		_, err = f.Write([]byte(content))
+		test.Ok(t, err)
+	}
+	test.Ok(t, w.Close())
+	test.Ok(t, file.Close())
+
+	return path, cleanup
+}
+
+func TestRead3MF(t *testing.T) {
+	var tests = map[string]struct {
+		unit     string
+		fileName string
+		min, max data.MicroVec3
+		faces    int
+	}{
+		"millimeter": {
+			unit:     "millimeter",
+			fileName: "model.3mf",
+			min:      data.NewMicroVec3(0, 0, 0),
+			max:      data.NewMicroVec3(12000, 12000, 2000),
+			faces:    8,
+		},
+		"centimeter": {
+			unit:     "centimeter",
+			fileName: "model.3mf",
+			min:      data.NewMicroVec3(0, 0, 0),
+			max:      data.NewMicroVec3(120000, 120000, 20000),
+			faces:    8,
+		},
+		"detected by content": {
+			unit:     "millimeter",
+			fileName: "model.unknown",
+			min:      data.NewMicroVec3(0, 0, 0),
+			max:      data.NewMicroVec3(12000, 12000, 2000),
+			faces:    8,
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log(name)
+
+		path, cleanup := write3MF(t, testCase.fileName, map[string]string{
+			"_rels/.rels":      rels3MF,
+			"3D/3dmodel.model": fmt.Sprintf(tetrahedron3MF, testCase.unit),
+		})
+
+		options := data.DefaultOptions()
+		model, err := reader.Reader(&options).Read(path)
+		cleanup()
+
+		test.Ok(t, err)
+		test.Equals(t, testCase.faces, model.FaceCount())
+		test.Equals(t, testCase.min.String(), model.Min().String())
+		test.Equals(t, testCase.max.String(), model.Max().String())
+	}
+}
+
+func TestRead3MFErrors(t *testing.T) {
+	var tests = map[string]map[string]string{
+		"missing model": {
+			"_rels/.rels": rels3MF,
+		},
+		"unknown unit": {
+			"3D/3dmodel.model": fmt.Sprintf(tetrahedron3MF, "lightyear"),
+		},
+		"empty build": {
+			"3D/3dmodel.model": `<model unit="millimeter"><resources /><build /></model>`,
+		},
+	}
+
+	for name, files := range tests {
+		t.Log(name)
+
+		path, cleanup := write3MF(t, "model.3mf", files)
+
+		options := data.DefaultOptions()
+		_, err := reader.Reader(&options).Read(path)
+		cleanup()
+
+		test.Assert(t, err != nil, "an error was expected")
+	}
+}
+
+// cubeOBJ is a cube of 10 x 10 x 10 mm using quads, negative indices and several groups.
+const cubeOBJ = `# cube
+o cube
+v 0 0 0
+v 10 0 0
+v 10 10 0
+v 0 10 0
+v 0 0 10
+v 10 0 10
+v 10 10 10
+v 0 10 10
+g bottom
+f 1 4 3 2
+g top
+f 5/1 6/2 7/3 8/4
+g sides
+f 1//1 2//1 6//1 5//1
+f -7 -6 -2 -3
+f 3 4 8 7
+f 4 1 5 8
+`
+
+func TestReadOBJ(t *testing.T) {
+	path, cleanup := writeTestFile(t, "cube.obj", []byte(cubeOBJ))
+	defer cleanup()
+
+	options := data.DefaultOptions()
+	model, err := reader.Reader(&options).Read(path)
+	test.Ok(t, err)
+
+	test.Equals(t, 12, model.FaceCount())
+	test.Equals(t, data.NewMicroVec3(0, 0, 0).String(), model.Min().String())
+	test.Equals(t, data.NewMicroVec3(10000, 10000, 10000).String(), model.Max().String())
+}
+
+func TestReadOBJErrors(t *testing.T) {
+	var tests = map[string]string{
+		"unknown vertex":  "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 4\n",
+		"invalid vertex":  "v 0 a 0\n",
+		"too few indices": "v 0 0 0\nv 1 0 0\nf 1 2\n",
+	}
+
+	for name, content := range tests {
+		t.Log(name)
+
+		path, cleanup := writeTestFile(t, "model.obj", []byte(content))
+
+		options := data.DefaultOptions()
+		_, err := reader.Reader(&options).Read(path)
+		cleanup()
+
+		test.Assert(t, err != nil, "an error was expected")
+	}
+}
+
+// plyHeader is the header of a PLY file containing a quad with an additional
+// vertex property and an additional element which have to be skipped.
+const plyHeader = `ply
+format %s 1.0
+comment test
+element vertex 4
+property float x
+property float y
+property float z
+property uchar red
+element face 1
+property list uchar int vertex_indices
+element edge 1
+property int vertex1
+property int vertex2
+end_header
+`
+
+func TestReadPLY(t *testing.T) {
+	vertices := [][3]float32{{0, 0, 0}, {10, 0, 0}, {10, 20, 0}, {0, 20, 5}}
+
+	binaryPLY := func(order binary.ByteOrder, format string) []byte {
+		buf := bytes.NewBufferString(fmt.Sprintf(plyHeader, format))
+		for _, v := range vertices {
+			test.Ok(t, binary.Write(buf, order, v))
+			buf.WriteByte(255)
+		}
+		buf.WriteByte(4)
+		test.Ok(t, binary.Write(buf, order, []int32{0, 1, 2, 3}))
+		test.Ok(t, binary.Write(buf, order, []int32{0, 1}))
+		return buf.Bytes()
+	}
+
+	var tests = map[string]struct {
+		fileName string
+		content  []byte
+	}{
+		"ascii": {
+			fileName: "model.ply",
+			content:  []byte(fmt.Sprintf(plyHeader, "ascii") + "0 0 0 255\n10 0 0 255\n10 20 0 255\n0 20 5 255\n4 0 1 2 3\n0 1\n"),
+		},
+		"binary little endian": {
+			fileName: "model.ply",
+			content:  binaryPLY(binary.LittleEndian, "binary_little_endian"),
+		},
+		"binary big endian": {
+			fileName: "model.ply",
+			content:  binaryPLY(binary.BigEndian, "binary_big_endian"),
+		},
+		"detected by content": {
+			fileName: "model.unknown",
+			content:  binaryPLY(binary.LittleEndian, "binary_little_endian"),
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log(name)
+
+		path, cleanup := writeTestFile(t, testCase.fileName, testCase.content)
+
+		options := data.DefaultOptions()
+		model, err := reader.Reader(&options).Read(path)
+		cleanup()
+
+		test.Ok(t, err)
+		test.Equals(t, 2, model.FaceCount())
+		test.Equals(t, data.NewMicroVec3(0, 0, 0).String(), model.Min().String())
+		test.Equals(t, data.NewMicroVec3(10000, 20000, 5000).String(), model.Max().String())
+	}
+}
+
+func TestReadPLYErrors(t *testing.T) {
+	var tests = map[string]string{
+		"truncated body": fmt.Sprintf(plyHeader, "ascii") + "0 0 0 255\n10 0 0\n",
+		"unknown format": fmt.Sprintf(plyHeader, "binary_middle_endian"),
+		"unknown vertex": "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty float z\nelement face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n3 0 1 2\n",
+	}
+
+	for name, content := range tests {
+		t.Log(name)
+
+		path, cleanup := writeTestFile(t, "model.ply", []byte(content))
+
+		options := data.DefaultOptions()
+		_, err := reader.Reader(&options).Read(path)
+		cleanup()
+
+		test.Assert(t, err != nil, "an error was expected")
+	}
+}
+
+// binarySTL creates a binary stl containing the given triangles.
+// The triangle count of the header can be set independently to create invalid files.
+func binarySTL(t *testing.T, count uint32, triangles [][3][3]float32) []byte {
+	buf := bytes.NewBuffer(make([]byte, 80))
+	test.Ok(t, binary.Write(buf, binary.LittleEndian, count))
+	for _, triangle := range triangles {
+		test.Ok(t, binary.Write(buf, binary.LittleEndian, [3]float32{}))
+		test.Ok(t, binary.Write(buf, binary.LittleEndian, triangle))
+		test.Ok(t, binary.Write(buf, binary.LittleEndian, uint16(0)))
+	}
+	return buf.Bytes()
+}
+
+var stlTriangles = [][3][3]float32{
+	{{0, 0, 0}, {10, 0, 0}, {0, 20, 0}},
+	{{0, 0, 0}, {10, 0, 0}, {0, 0, 5}},
+}
+
+const asciiSTL = `solid test
+facet normal 0 0 0
+  outer loop
+    vertex 0 0 0
+    vertex 10 0 0
+    vertex 0 20 0
+  endloop
+endfacet
+facet normal 0 0 0
+  outer loop
+    vertex 0 0 0
+    vertex 10 0 0
+    vertex 0 0 %s
+  endloop
+endfacet
+endsolid test
+`
+
+func TestReadSTL(t *testing.T) {
+	var tests = map[string]struct {
+		fileName string
+		content  []byte
+	}{
+		"binary": {
+			fileName: "model.stl",
+			content:  binarySTL(t, 2, stlTriangles),
+		},
+		"binary starting with solid": {
+			fileName: "model.stl",
+			content:  append([]byte("solid"), binarySTL(t, 2, stlTriangles)[5:]...),
+		},
+		"ascii": {
+			fileName: "model.stl",
+			content:  []byte(fmt.Sprintf(asciiSTL, "5")),
+		},
+		"detected by content": {
+			fileName: "model.unknown",
+			content:  binarySTL(t, 2, stlTriangles),
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log(name)
+
+		path, cleanup := writeTestFile(t, testCase.fileName, testCase.content)
+
+		options := data.DefaultOptions()
+		model, err := reader.Reader(&options).Read(path)
+		cleanup()
+
+		test.Ok(t, err)
+		test.Equals(t, 2, model.FaceCount())
+		test.Equals(t, data.NewMicroVec3(0, 0, 0).String(), model.Min().String())
+		test.Equals(t, data.NewMicroVec3(10000, 20000, 5000).String(), model.Max().String())
+	}
+}
+
+func TestReadSTLErrors(t *testing.T) {
+	nanTriangles := [][3][3]float32{
+		stlTriangles[0],
+		{{0, 0, 0}, {float32(math.NaN()), 0, 0}, {0, 0, 5}},
+	}
+
+	var tests = map[string]struct {
+		content []byte
+		check   func(err error) bool
+	}{
+		"incomplete header": {
+			content: make([]byte, 40),
+			check: func(err error) bool {
+				var truncated *reader.TruncatedError
+				return errors.As(err, &truncated) && truncated.TriangleIndex == -1
+			},
+		},
+		"truncated": {
+			content: binarySTL(t, 2, stlTriangles)[:84+50+20],
+			check: func(err error) bool {
+				var truncated *reader.TruncatedError
+				return errors.As(err, &truncated) && truncated.TriangleIndex == 1
+			},
+		},
+		"triangle count mismatch": {
+			content: binarySTL(t, 5, stlTriangles),
+			check: func(err error) bool {
+				var count *reader.TriangleCountError
+				return errors.As(err, &count) && count.Expected == 5 && count.Actual == 2
+			},
+		},
+		"binary NaN vertex": {
+			content: binarySTL(t, 2, nanTriangles),
+			check: func(err error) bool {
+				var invalid *reader.InvalidVertexError
+				return errors.As(err, &invalid) && invalid.TriangleIndex == 1 && invalid.VertexIndex == 1
+			},
+		},
+		"ascii infinite vertex": {
+			content: []byte(fmt.Sprintf(asciiSTL, "inf")),
+			check: func(err error) bool {
+				var invalid *reader.InvalidVertexError
+				return errors.As(err, &invalid) && invalid.TriangleIndex == 1 && invalid.VertexIndex == 2
+			},
+		},
+		"empty binary": {
+			content: binarySTL(t, 0, nil),
+			check: func(err error) bool {
+				return errors.Is(err, reader.ErrEmptyModel)
+			},
+		},
+		"empty ascii": {
+			content: []byte("solid test\nendsolid test\n"),
+			check: func(err error) bool {
+				return errors.Is(err, reader.ErrEmptyModel)
+			},
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log(name)
+
+		path, cleanup := writeTestFile(t, "model.stl", testCase.content)
+
+		options := data.DefaultOptions()
+		_, err := reader.Reader(&options).Read(path)
+		cleanup()
+
+		test.Assert(t, testCase.check(err), "unexpected error %v", err)
+	}
+}
+
+func TestReadEmptyModel(t *testing.T) {
+	var tests = map[string]string{
+		"model.obj": "# nothing\n",
+		"model.ply": "ply\nformat ascii 1.0\nelement vertex 0\nproperty float x\nproperty float y\nproperty float z\nend_header\n",
+	}
+
+	for fileName, content := range tests {
+		t.Log(fileName)
+
+		path, cleanup := writeTestFile(t, fileName, []byte(content))
+
+		options := data.DefaultOptions()
+		_, err := reader.Reader(&options).Read(path)
+		cleanup()
+
+		test.Assert(t, errors.Is(err, reader.ErrEmptyModel), "unexpected error %v", err)
+	}
+}
+
+func TestReadStream(t *testing.T) {
+	var threeMF bytes.Buffer
+	w := zip.NewWriter(&threeMF)
+	f, err := w.Create("3D/3dmodel.model")
+	test.Ok(t, err)
+	_, err = f.Write([]byte(fmt.Sprintf(tetrahedron3MF, "millimeter")))
+	test.Ok(t, err)
+	test.Ok(t, w.Close())
+
+	var tests = map[string]struct {
+		content   []byte
+		extension string
+		faces     int
+	}{
+		"3mf": {
+			content:   threeMF.Bytes(),
+			extension: ".3mf",
+			faces:     8,
+		},
+		"obj": {
+			content:   []byte(cubeOBJ),
+			extension: ".obj",
+			faces:     12,
+		},
+		"ply": {
+			content:   []byte(fmt.Sprintf(plyHeader, "ascii") + "0 0 0 255\n10 0 0 255\n10 20 0 255\n0 20 5 255\n4 0 1 2 3\n0 1\n"),
+			extension: ".ply",
+			faces:     2,
+		},
+		"binary stl": {
+			content:   binarySTL(t, 2, stlTriangles),
+			extension: ".stl",
+			faces:     2,
+		},
+		"ascii stl": {
+			content:   []byte(fmt.Sprintf(asciiSTL, "5")),
+			extension: ".stl",
+			faces:     2,
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log(name)
+
+		options := data.DefaultOptions()
+		streamReader, ok := reader.Reader(&options).(handler.ModelStreamReader)
+		test.Assert(t, ok, "the reader should support streams")
+
+		model, err := streamReader.ReadStream(bytes.NewReader(testCase.content))
+		test.Ok(t, err)
+		test.Equals(t, testCase.faces, model.FaceCount())
+
+		// Files are read by their extension.
+		path, cleanup := writeTestFile(t, "model"+testCase.extension, testCase.content)
+		file, err := os.Open(path)
+		test.Ok(t, err)
+		model, err = streamReader.ReadStream(file)
+		_ = file.Close()
+		cleanup()
+		test.Ok(t, err)
+		test.Equals(t, testCase.faces, model.FaceCount())
+	}
+}
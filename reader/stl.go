@@ -0,0 +1,209 @@
+package reader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/handler"
+	"github.com/hschendel/stl"
+)
+
+const (
+	// stlBinaryHeaderSize is the size of the 80 byte header plus the 4 byte triangle count.
+	stlBinaryHeaderSize = 84
+
+	// stlBinaryTriangleSize is the size of the normal, the three vertices and the attribute byte count.
+	stlBinaryTriangleSize = 50
+
+	// stlASCIIDetectionSize is the amount of bytes checked for ascii keywords.
+	stlASCIIDetectionSize = 512
+)
+
+type stlReader struct{}
+
+// STLReader returns a stl model reader which also implements handler.ModelStreamReader.
+// It supports ascii and binary stl files and validates them while reading.
+// Errors are reported using the error types of this package (e.g. *TruncatedError).
+func STLReader(options *data.Options) handler.ModelReader {
+	return &stlReader{}
+}
+
+func (r stlReader) Read(filename string) (data.Model, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return readSTL(file, info.Size())
+}
+
+func (r stlReader) ReadStream(in io.Reader) (data.Model, error) {
+	// The whole file is needed to detect the format and to validate the size of binary files.
+	content, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return readSTL(bytes.NewReader(content), int64(len(content)))
+}
+
+// readSTL reads an ascii or binary stl with the given size.
+func readSTL(r io.ReadSeeker, size int64) (data.Model, error) {
+	head := make([]byte, stlASCIIDetectionSize)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if isASCIISTL(head, size) {
+		return readASCIISTL(r)
+	}
+	return readBinarySTL(r, size)
+}
+
+// isASCIISTL checks if the beginning of the file looks like an ascii stl.
+// Binary files may also start with "solid", so the size and the keywords are checked, too.
+func isASCIISTL(head []byte, size int64) bool {
+	if !bytes.HasPrefix(bytes.TrimLeft(head, " \t\r\n"), []byte("solid")) {
+		return false
+	}
+
+	if len(head) >= stlBinaryHeaderSize {
+		count := int64(binary.LittleEndian.Uint32(head[80:stlBinaryHeaderSize]))
+		if size == stlBinaryHeaderSize+count*stlBinaryTriangleSize {
+			return false
+		}
+	}
+
+	return bytes.Contains(head, []byte("facet")) || bytes.Contains(head, []byte("endsolid"))
+}
+
+// readASCIISTL reads an ascii stl using the stl package and validates the result.
+func readASCIISTL(r io.ReadSeeker) (data.Model, error) {
+	solid := &stl.Solid{}
+	if err := stl.CopyAll(r, solid); err != nil {
+		return nil, fmt.Errorf("stl: %w", err)
+	}
+
+	model := &Model{}
+	for i, t := range solid.Triangles {
+		f, err := newFace(i, stlTrianglePoints(t))
+		if err != nil {
+			return nil, err
+		}
+		model.faces = append(model.faces, f)
+	}
+
+	if len(model.faces) == 0 {
+		return nil, ErrEmptyModel
+	}
+
+	return model, nil
+}
+
+// readBinarySTL reads a binary stl with the given size.
+// The triangle count of the header is checked against the file size before reading the triangles.
+func readBinarySTL(r io.Reader, size int64) (data.Model, error) {
+	br := bufio.NewReader(r)
+
+	var header [stlBinaryHeaderSize]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, &TruncatedError{TriangleIndex: -1}
+		}
+		return nil, err
+	}
+
+	count := int64(binary.LittleEndian.Uint32(header[80:]))
+	remaining := size - stlBinaryHeaderSize
+	if remaining < count*stlBinaryTriangleSize && remaining%stlBinaryTriangleSize != 0 {
+		return nil, &TruncatedError{TriangleIndex: int(remaining / stlBinaryTriangleSize)}
+	}
+	if remaining/stlBinaryTriangleSize != count {
+		return nil, &TriangleCountError{Expected: int(count), Actual: int(remaining / stlBinaryTriangleSize)}
+	}
+	if count == 0 {
+		return nil, ErrEmptyModel
+	}
+
+	model := &Model{
+		faces: make([]data.Face, 0, count),
+	}
+	var buf [stlBinaryTriangleSize]byte
+	for i := 0; i < int(count); i++ {
+		if _, err := io.ReadFull(br, buf[:]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil, &TruncatedError{TriangleIndex: i}
+			}
+			return nil, err
+		}
+
+		// Skip the normal (12 bytes) and read the three vertices.
+		var points [3][3]float64
+		for j := range points {
+			for k := range points[j] {
+				offset := 12 + j*12 + k*4
+				points[j][k] = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[offset:])))
+			}
+		}
+
+		f, err := newFace(i, points)
+		if err != nil {
+			return nil, err
+		}
+		model.faces = append(model.faces, f)
+	}
+
+	return model, nil
+}
+
+// stlTrianglePoints converts the vertices of a triangle from the stl package
+// into points in millimeter.
+func stlTrianglePoints(t stl.Triangle) [3][3]float64 {
+	var points [3][3]float64
+	for i, v := range t.Vertices {
+		points[i] = [3]float64{float64(v[0]), float64(v[1]), float64(v[2])}
+	}
+	return points
+}
+
+// stlTriangleToFace converts a triangle from the stl package
+// into a face.
+func stlTriangleToFace(t stl.Triangle) face {
+	return face{vectors: [3]data.MicroVec3{
+		data.NewMicroVec3(
+			data.Millimeter(t.Vertices[0][0]).ToMicrometer(),
+			data.Millimeter(t.Vertices[0][1]).ToMicrometer(),
+			data.Millimeter(t.Vertices[0][2]).ToMicrometer()),
+		data.NewMicroVec3(
+			data.Millimeter(t.Vertices[1][0]).ToMicrometer(),
+			data.Millimeter(t.Vertices[1][1]).ToMicrometer(),
+			data.Millimeter(t.Vertices[1][2]).ToMicrometer()),
+		data.NewMicroVec3(
+			data.Millimeter(t.Vertices[2][0]).ToMicrometer(),
+			data.Millimeter(t.Vertices[2][1]).ToMicrometer(),
+			data.Millimeter(t.Vertices[2][2]).ToMicrometer()),
+	}}
+}
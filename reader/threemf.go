@@ -0,0 +1,291 @@
+// This file provides a reader for 3MF files.
+//
+// A 3MF file is a zip archive which contains (among other things) a xml file describing the model.
+// The model consists of several objects which either contain a mesh or reference other objects as components.
+// The build section then lists the objects which should actually be printed.
+// Build items as well as components can transform the referenced object by an affine matrix.
+//
+// See https://3mf.io/specification/ for the full specification.
+
+package reader
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/handler"
+)
+
+const (
+	// threeMFModelRelationship is the relationship type of the main model part.
+	threeMFModelRelationship = "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"
+
+	// threeMFDefaultModelPath is used if the relationships do not point to a model part.
+	threeMFDefaultModelPath = "3D/3dmodel.model"
+
+	// threeMFMaxComponentDepth limits the nesting of components to detect circular references.
+	threeMFMaxComponentDepth = 32
+)
+
+// threeMFUnits maps all units allowed by the 3MF specification to their size in millimeter.
+var threeMFUnits = map[string]float64{
+	"micron":     0.001,
+	"millimeter": 1,
+	"centimeter": 10,
+	"inch":       25.4,
+	"foot":       304.8,
+	"meter":      1000,
+}
+
+type threeMFRelationships struct {
+	Relationships []struct {
+		Target string `xml:"Target,attr"`
+		Type   string `xml:"Type,attr"`
+	} `xml:"Relationship"`
+}
+
+type threeMFModel struct {
+	Unit    string          `xml:"unit,attr"`
+	Objects []threeMFObject `xml:"resources>object"`
+	Items   []struct {
+		ObjectID  int    `xml:"objectid,attr"`
+		Transform string `xml:"transform,attr"`
+	} `xml:"build>item"`
+}
+
+type threeMFObject struct {
+	ID   int    `xml:"id,attr"`
+	Type string `xml:"type,attr"`
+
+	Vertices []struct {
+		X float64 `xml:"x,attr"`
+		Y float64 `xml:"y,attr"`
+		Z float64 `xml:"z,attr"`
+	} `xml:"mesh>vertices>vertex"`
+	Triangles []struct {
+		V1 int `xml:"v1,attr"`
+		V2 int `xml:"v2,attr"`
+		V3 int `xml:"v3,attr"`
+	} `xml:"mesh>triangles>triangle"`
+
+	Components []struct {
+		ObjectID  int    `xml:"objectid,attr"`
+		Transform string `xml:"transform,attr"`
+	} `xml:"components>component"`
+}
+
+type threeMFReader struct{}
+
+// ThreeMFReader returns a 3MF model reader which also implements handler.ModelStreamReader.
+// All build items are merged into one model using their transformations.
+func ThreeMFReader(options *data.Options) handler.ModelReader {
+	return &threeMFReader{}
+}
+
+func (r threeMFReader) Read(filename string) (data.Model, error) {
+	archive, err := zip.OpenReader(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	defer archive.Close()
+
+	return readThreeMF(&archive.Reader)
+}
+
+func (r threeMFReader) ReadStream(in io.Reader) (data.Model, error) {
+	// A zip archive can only be read with random access.
+	content, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("3mf: %w", err)
+	}
+
+	return readThreeMF(archive)
+}
+
+// readThreeMF reads the model out of the already opened 3MF archive.
+func readThreeMF(archive *zip.Reader) (data.Model, error) {
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[strings.TrimPrefix(file.Name, "/")] = file
+	}
+
+	modelPath := threeMFDefaultModelPath
+	if relsFile, ok := files["_rels/.rels"]; ok {
+		var rels threeMFRelationships
+		if err := decodeXMLFile(relsFile, &rels); err != nil {
+			return nil, fmt.Errorf("3mf: could not read the relationships: %w", err)
+		}
+
+		for _, rel := range rels.Relationships {
+			if rel.Type == threeMFModelRelationship {
+				modelPath = strings.TrimPrefix(path.Clean("/"+rel.Target), "/")
+				break
+			}
+		}
+	}
+
+	modelFile, ok := files[modelPath]
+	if !ok {
+		return nil, fmt.Errorf("3mf: the model part %s does not exist", modelPath)
+	}
+
+	var model threeMFModel
+	if err := decodeXMLFile(modelFile, &model); err != nil {
+		return nil, fmt.Errorf("3mf: could not read the model: %w", err)
+	}
+
+	unit := "millimeter"
+	if model.Unit != "" {
+		unit = model.Unit
+	}
+	unitScale, ok := threeMFUnits[unit]
+	if !ok {
+		return nil, fmt.Errorf("3mf: unknown unit %s", unit)
+	}
+
+	objects := map[int]*threeMFObject{}
+	for i := range model.Objects {
+		objects[model.Objects[i].ID] = &model.Objects[i]
+	}
+
+	if len(model.Items) == 0 {
+		return nil, errors.New("3mf: the build does not contain any items")
+	}
+
+	// The unit is applied last so that the transformations
+	// (which are given in the unit of the model) stay valid.
+	scale := data.IdentityMatrix()
+	scale[0][0] = unitScale
+	scale[1][1] = unitScale
+	scale[2][2] = unitScale
+
+	result := &Model{}
+	for _, item := range model.Items {
+		transform, err := parseThreeMFTransform(item.Transform)
+		if err != nil {
+			return nil, err
+		}
+
+		object, ok := objects[item.ObjectID]
+		if !ok {
+			return nil, fmt.Errorf("3mf: the build references the unknown object %d", item.ObjectID)
+		}
+
+		// Objects which are not a model (e.g. support or other) are not printed.
+		if object.Type != "" && object.Type != "model" {
+			continue
+		}
+
+		err = appendThreeMFObject(result, objects, object, scale.Mul(transform), 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(result.faces) == 0 {
+		return nil, ErrEmptyModel
+	}
+
+	return result, nil
+}
+
+// appendThreeMFObject adds the mesh and all components of the object to the model.
+func appendThreeMFObject(model *Model, objects map[int]*threeMFObject, object *threeMFObject, transform data.Matrix, depth int) error {
+	if depth > threeMFMaxComponentDepth {
+		return fmt.Errorf("3mf: the components of object %d are nested too deep", object.ID)
+	}
+
+	for i, triangle := range object.Triangles {
+		var points [3][3]float64
+		for j, vertexIndex := range [3]int{triangle.V1, triangle.V2, triangle.V3} {
+			if vertexIndex < 0 || vertexIndex >= len(object.Vertices) {
+				return fmt.Errorf("3mf: triangle %d of object %d references the unknown vertex %d", i, object.ID, vertexIndex)
+			}
+
+			v := object.Vertices[vertexIndex]
+			points[j][0], points[j][1], points[j][2] = transform.Apply(v.X, v.Y, v.Z)
+		}
+
+		f, err := newFace(len(model.faces), points)
+		if err != nil {
+			return fmt.Errorf("3mf: triangle %d of object %d: %w", i, object.ID, err)
+		}
+		model.faces = append(model.faces, f)
+	}
+
+	for _, component := range object.Components {
+		componentTransform, err := parseThreeMFTransform(component.Transform)
+		if err != nil {
+			return err
+		}
+
+		child, ok := objects[component.ObjectID]
+		if !ok {
+			return fmt.Errorf("3mf: object %d references the unknown object %d", object.ID, component.ObjectID)
+		}
+
+		err = appendThreeMFObject(model, objects, child, transform.Mul(componentTransform), depth+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseThreeMFTransform parses the transform attribute used by build items and components.
+// It consists of 12 values "m00 m01 m02 m10 m11 m12 m20 m21 m22 m30 m31 m32"
+// which are applied to row vectors: [x y z 1] * M.
+// An empty string results in the identity matrix.
+func parseThreeMFTransform(s string) (data.Matrix, error) {
+	m := data.IdentityMatrix()
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) != 12 {
+		return m, fmt.Errorf("3mf: the transform %q needs exactly 12 values", s)
+	}
+
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return m, fmt.Errorf("3mf: invalid value in transform %q: %w", s, err)
+		}
+
+		// The 3MF matrix is transposed compared to data.Matrix.
+		m[i%3][i/3] = value
+	}
+
+	return m, nil
+}
+
+// decodeXMLFile decodes the given xml file of the archive into v.
+func decodeXMLFile(file *zip.File, v interface{}) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return xml.NewDecoder(rc).Decode(v)
+}
@@ -0,0 +1,126 @@
+// This file provides the calculation of the layer heights.
+
+package slicer
+
+import (
+	"math"
+	"sort"
+
+	"github.com/aligator/goslice/data"
+)
+
+// layerHeights returns the height of the top of each layer.
+// If options.Print.AdaptiveLayers is disabled all layers but the first one have the same thickness.
+func layerHeights(m data.OptimizedModel, options *data.Options) []data.Micrometer {
+	if options.Print.AdaptiveLayers {
+		return adaptiveLayerHeights(m, options)
+	}
+
+	layerCount := int((m.Size().Z()-options.Print.InitialLayerThickness)/options.Print.LayerThickness + 1)
+	if layerCount < 0 {
+		layerCount = 0
+	}
+
+	heights := make([]data.Micrometer, layerCount)
+	for layerNr := range heights {
+		heights[layerNr] = options.Print.InitialLayerThickness + data.Micrometer(layerNr)*options.Print.LayerThickness
+	}
+
+	return heights
+}
+
+// slope is a face which limits the thickness of all layers between its min and max height.
+type slope struct {
+	minZ, maxZ data.Micrometer
+	thickness  data.Micrometer
+}
+
+// adaptiveLayerHeights calculates the layer heights so that the steps between two layers on the surface of the model
+// are never bigger than the min layer thickness.
+// A face with the normal n creates steps of layerThickness * |n.z|, so the thickness of all layers
+// which cut it is limited to MinLayerThickness / |n.z|.
+// Vertical walls can use the MaxLayerThickness, nearly flat slopes need the MinLayerThickness.
+// Flat faces are ignored as they do not create any steps.
+func adaptiveLayerHeights(m data.OptimizedModel, options *data.Options) []data.Micrometer {
+	minThickness := options.Print.MinLayerThickness
+	maxThickness := options.Print.MaxLayerThickness
+	if minThickness > maxThickness {
+		minThickness = maxThickness
+	}
+
+	var slopes []slope
+	for i := 0; i < m.FaceCount(); i++ {
+		face := m.OptimizedFace(i)
+		minZ, maxZ := face.MinZ(), face.MaxZ()
+		if minZ == maxZ {
+			continue
+		}
+
+		points := face.Points()
+		a := points[1].Sub(points[0])
+		b := points[2].Sub(points[0])
+		ax, ay, az := float64(a.X()), float64(a.Y()), float64(a.Z())
+		bx, by, bz := float64(b.X()), float64(b.Y()), float64(b.Z())
+		nx, ny, nz := ay*bz-az*by, az*bx-ax*bz, ax*by-ay*bx
+		length := math.Sqrt(nx*nx + ny*ny + nz*nz)
+		if length == 0 || nz == 0 {
+			continue
+		}
+
+		thickness := float64(minThickness) / math.Abs(nz/length)
+		if thickness >= float64(maxThickness) {
+			continue
+		}
+
+		slopes = append(slopes, slope{
+			minZ:      minZ,
+			maxZ:      maxZ,
+			thickness: data.Micrometer(thickness),
+		})
+	}
+
+	sort.Slice(slopes, func(i, j int) bool {
+		return slopes[i].minZ < slopes[j].minZ
+	})
+
+	height := m.Size().Z()
+	z := options.Print.InitialLayerThickness
+	heights := []data.Micrometer{z}
+
+	var active []slope
+	next := 0
+	for z < height {
+		// Add all slopes which start below the thickest possible layer.
+		for next < len(slopes) && slopes[next].minZ < z+maxThickness {
+			active = append(active, slopes[next])
+			next++
+		}
+
+		// Remove all slopes which end below the layer and use the thinnest layer of the remaining ones.
+		thickness := maxThickness
+		remaining := active[:0]
+		for _, s := range active {
+			if s.maxZ <= z {
+				continue
+			}
+			remaining = append(remaining, s)
+			if s.thickness < thickness {
+				thickness = s.thickness
+			}
+		}
+		active = remaining
+
+		// The last layer ends at the top of the model if it is not too thin.
+		if z+thickness > height {
+			thickness = height - z
+			if thickness < minThickness {
+				break
+			}
+		}
+
+		z += thickness
+		heights = append(heights, z)
+	}
+
+	return heights
+}
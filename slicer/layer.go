@@ -11,13 +11,23 @@ type layer struct {
 	polygons           data.Paths
 	closed             []bool
 	number             int
+	z                  data.Micrometer
+	thickness          data.Micrometer
 }
 
-func newLayer(number int, options *data.Options) *layer {
+// newLayer creates the layer with the given number using the heights of all layers.
+func newLayer(number int, heights []data.Micrometer, options *data.Options) *layer {
+	thickness := heights[number]
+	if number > 0 {
+		thickness -= heights[number-1]
+	}
+
 	return &layer{
 		options:            options,
 		faceToSegmentIndex: map[int]int{},
 		number:             number,
+		z:                  heights[number],
+		thickness:          thickness,
 	}
 }
 
@@ -25,6 +35,14 @@ func (l *layer) Polygons() data.Paths {
 	return l.polygons
 }
 
+func (l *layer) Z() data.Micrometer {
+	return l.z
+}
+
+func (l *layer) Thickness() data.Micrometer {
+	return l.thickness
+}
+
 // makePolygons is responsible for creating polygons out of the list of loose segments received through slicing the faces.
 // For this it loops through all segments (which are not already part of a polygon) and then tries to build the whole polygon
 // by iterating through all touching faces of the face the segment comes from. If a segment is found it is done again the same
@@ -1,8 +1,9 @@
 // Package slicer provides an implementation for slicing a model into 2d slices.
 //
 // How it works:
+// First the height of each layer is calculated (see layerHeights).
 // For each face (always a triangle) it first fetches the min and max height (z) and then the face is sliced at each
-// needed layer height (taking into account the optionally different initial layer thickness).
+// layer height between them.
 // For this it first determines which of the three points is below or above the current z height and then based on this
 // calls the SliceFace function which simply returns a segment which is one line (2 points) representing the slice of the triangle
 // at exactly the current height.
@@ -15,10 +16,14 @@
 package slicer
 
 import (
+	"context"
 	"fmt"
 	"github.com/aligator/goslice/clip"
 	"github.com/aligator/goslice/data"
 	"github.com/aligator/goslice/handler"
+	"github.com/aligator/goslice/util/parallel"
+	"sort"
+	"sync"
 )
 
 type slicer struct {
@@ -30,12 +35,65 @@ func NewSlicer(options *data.Options) handler.ModelSlicer {
 	return &slicer{options: options}
 }
 
-func (s slicer) Slice(m data.OptimizedModel) ([]data.PartitionedLayer, error) {
-	layerCount := (m.Size().Z()-s.options.Print.InitialLayerThickness)/s.options.Print.LayerThickness + 1
+func (s slicer) Slice(ctx context.Context, m data.OptimizedModel) ([]data.PartitionedLayer, error) {
+	heights := layerHeights(m, s.options)
+	layerCount := len(heights)
 
-	layers := make([]*layer, layerCount)
+	// Slice the faces in several shards of contiguous faces.
+	// As the shards are merged in order afterwards, the segments are in the same order as if all faces were sliced at once.
+	shardCount := parallel.Workers(s.options.GoSlice.Concurrency)
+	if shardCount > m.FaceCount() {
+		shardCount = m.FaceCount()
+	}
+	shards := make([][]*layer, shardCount)
+	err := parallel.ForEach(ctx, shardCount, shardCount, func(shard int) error {
+		shards[shard] = s.sliceFaces(m, heights, m.FaceCount()*shard/shardCount, m.FaceCount()*(shard+1)/shardCount)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	retLayers := make([]data.PartitionedLayer, layerCount)
+	c := clip.NewClipper()
+
+	var mutex sync.Mutex
+	finished := 0
+
+	err = parallel.ForEach(ctx, s.options.GoSlice.Concurrency, layerCount, func(layerNr int) error {
+		layer := mergeShards(shards, layerNr, heights, s.options)
+		layer.makePolygons(m, s.options.Slicing.JoinPolygonSnapDistance, s.options.Slicing.FinishPolygonSnapDistance)
+		lp, ok := c.GenerateLayerParts(layer)
 
-	for i := 0; i < m.FaceCount(); i++ {
+		if !ok {
+			return fmt.Errorf("partitioning failed at layer %v", layerNr)
+		}
+
+		retLayers[layerNr] = lp
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		finished++
+		s.options.GoSlice.Progress.Report(data.Progress{
+			Stage:      data.StageSlice,
+			Layer:      finished,
+			LayerCount: layerCount,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return retLayers, nil
+}
+
+// sliceFaces slices the faces from start (inclusive) to end (exclusive) into segments at the given layer heights.
+// It returns the layers containing the segments. Layers without segments are nil.
+func (s slicer) sliceFaces(m data.OptimizedModel, heights []data.Micrometer, start, end int) []*layer {
+	layers := make([]*layer, len(heights))
+
+	for i := start; i < end; i++ {
 		points := m.Face(i).Points()
 		minZ := points[0].Z()
 		maxZ := points[0].Z()
@@ -54,18 +112,15 @@ func (s slicer) Slice(m data.OptimizedModel) ([]data.PartitionedLayer, error) {
 			maxZ = points[2].Z()
 		}
 
-		// for each layerNr
-		for layerNr := int((minZ - s.options.Print.InitialLayerThickness) / s.options.Print.LayerThickness); data.Micrometer(layerNr) <= (maxZ-s.options.Print.InitialLayerThickness)/s.options.Print.LayerThickness; layerNr++ {
-			z := data.Micrometer(layerNr)*s.options.Print.LayerThickness + s.options.Print.InitialLayerThickness
-			if z < minZ {
-				continue
-			}
-			if layerNr < 0 {
-				continue
-			}
+		// for each layerNr between minZ and maxZ
+		firstLayerNr := sort.Search(len(heights), func(i int) bool {
+			return heights[i] >= minZ
+		})
+		for layerNr := firstLayerNr; layerNr < len(heights) && heights[layerNr] <= maxZ; layerNr++ {
+			z := heights[layerNr]
 
 			if layers[layerNr] == nil {
-				layers[layerNr] = newLayer(layerNr, s.options)
+				layers[layerNr] = newLayer(layerNr, heights, s.options)
 			}
 
 			layer := layers[layerNr]
@@ -107,19 +162,31 @@ func (s slicer) Slice(m data.OptimizedModel) ([]data.PartitionedLayer, error) {
 		}
 	}
 
-	retLayers := make([]data.PartitionedLayer, len(layers))
-	c := clip.NewClipper()
+	return layers
+}
 
-	for i, layer := range layers {
-		layer.makePolygons(m, s.options.Slicing.JoinPolygonSnapDistance, s.options.Slicing.FinishPolygonSnapDistance)
-		lp, ok := c.GenerateLayerParts(layer)
+// mergeShards combines the segments of the given layer of all shards into one layer.
+// The shards have to be ordered by their faces.
+func mergeShards(shards [][]*layer, layerNr int, heights []data.Micrometer, options *data.Options) *layer {
+	merged := newLayer(layerNr, heights, options)
+	for _, shard := range shards {
+		part := shard[layerNr]
+		if part == nil {
+			continue
+		}
 
-		if !ok {
-			return nil, fmt.Errorf("partitioning failed at layer %v", i)
+		// Just use the layer directly if it is the only one to avoid copying.
+		if len(merged.segments) == 0 {
+			merged = part
+			continue
 		}
 
-		retLayers[i] = lp
+		offset := len(merged.segments)
+		for faceIndex, segmentIndex := range part.faceToSegmentIndex {
+			merged.faceToSegmentIndex[faceIndex] = segmentIndex + offset
+		}
+		merged.segments = append(merged.segments, part.segments...)
 	}
 
-	return retLayers, nil
+	return merged
 }
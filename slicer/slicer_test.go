@@ -0,0 +1,144 @@
+package slicer_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/optimizer"
+	"github.com/aligator/goslice/reader"
+	"github.com/aligator/goslice/slicer"
+	"github.com/aligator/goslice/util/test"
+)
+
+const gopher = "../test_stl/gopher_union.stl"
+
+// loadModel reads and optimizes the given model.
+func loadModel(tb testing.TB, path string) data.OptimizedModel {
+	options := data.DefaultOptions()
+	options.GoSlice.Logger = log.New(ioutil.Discard, "", 0)
+
+	model, err := reader.Reader(&options).Read(path)
+	test.Ok(tb, err)
+
+	optimizedModel, err := optimizer.NewOptimizer(&options).Optimize(model)
+	test.Ok(tb, err)
+
+	return optimizedModel
+}
+
+// layerString converts all parts of the layer into a string which can be compared easily.
+func layerString(layer data.PartitionedLayer) string {
+	var b strings.Builder
+	for _, part := range layer.LayerParts() {
+		for _, path := range append(data.Paths{part.Outline()}, part.Holes()...) {
+			for _, p := range path {
+				_, _ = fmt.Fprintf(&b, "%v,%v ", p.X(), p.Y())
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("---\n")
+	}
+	return b.String()
+}
+
+func TestConcurrentSliceMatchesSerial(t *testing.T) {
+	model := loadModel(t, gopher)
+
+	var results [][]string
+	for _, concurrency := range []int{1, 3, 8} {
+		options := data.DefaultOptions()
+		options.GoSlice.Concurrency = concurrency
+
+		layers, err := slicer.NewSlicer(&options).Slice(context.Background(), model)
+		test.Ok(t, err)
+
+		var result []string
+		for _, layer := range layers {
+			result = append(result, layerString(layer))
+		}
+		results = append(results, result)
+	}
+
+	for _, result := range results[1:] {
+		test.Equals(t, len(results[0]), len(result))
+		for layerNr := range result {
+			test.Assert(t, results[0][layerNr] == result[layerNr], "layer %v differs from the serial result", layerNr)
+		}
+	}
+}
+
+func TestLayerHeights(t *testing.T) {
+	model := loadModel(t, gopher)
+
+	var tests = map[string]struct {
+		adaptive       bool
+		minThickness   data.Micrometer
+		maxThickness   data.Micrometer
+		differentSizes bool
+	}{
+		"fixed": {
+			adaptive:     false,
+			minThickness: 200,
+			maxThickness: 200,
+		},
+		"adaptive": {
+			adaptive:       true,
+			minThickness:   100,
+			maxThickness:   300,
+			differentSizes: true,
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log("test case:", name)
+
+		options := data.DefaultOptions()
+		options.GoSlice.Logger = log.New(ioutil.Discard, "", 0)
+		options.Print.AdaptiveLayers = testCase.adaptive
+
+		layers, err := slicer.NewSlicer(&options).Slice(context.Background(), model)
+		test.Ok(t, err)
+
+		test.Equals(t, options.Print.InitialLayerThickness, layers[0].Z())
+		test.Equals(t, options.Print.InitialLayerThickness, layers[0].Thickness())
+		test.Assert(t, layers[len(layers)-1].Z() <= model.Size().Z(), "the last layer should not be above the model")
+
+		sizes := map[data.Micrometer]bool{}
+		for layerNr := 1; layerNr < len(layers); layerNr++ {
+			thickness := layers[layerNr].Thickness()
+			sizes[thickness] = true
+			test.Equals(t, layers[layerNr-1].Z()+thickness, layers[layerNr].Z())
+			test.Assert(t, thickness >= testCase.minThickness && thickness <= testCase.maxThickness, "layer %v has a thickness of %v", layerNr, thickness)
+		}
+		test.Equals(t, testCase.differentSizes, len(sizes) > 1)
+	}
+}
+
+func BenchmarkSlice(b *testing.B) {
+	model := loadModel(b, gopher)
+
+	for _, concurrency := range []int{1, 0} {
+		options := data.DefaultOptions()
+		options.GoSlice.Concurrency = concurrency
+		s := slicer.NewSlicer(&options)
+
+		name := "serial"
+		if concurrency == 0 {
+			name = "concurrent"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, err := s.Slice(context.Background(), model)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
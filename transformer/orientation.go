@@ -0,0 +1,258 @@
+// This file provides the automatic orientation of a model (lay flat).
+//
+// How it works:
+// All faces are grouped by their normal. The 64 groups with the biggest area are used as candidates for the side
+// which should face down. Smaller groups are not evaluated, even if they would be a stable side to lie on.
+// For each candidate the model is evaluated as if it was rotated so that the candidate faces down:
+// - The contact area is the area of all faces lying on the bed.
+// - The overhang area is the area of all other faces which face down steeper than the support threshold angle.
+// The candidate with the biggest contact area minus overhang area wins.
+
+package transformer
+
+import (
+	"math"
+	"sort"
+
+	"github.com/aligator/goslice/data"
+)
+
+const (
+	// orientationCandidates is the max amount of normal groups which are evaluated.
+	orientationCandidates = 64
+
+	// normalGroupPrecision defines the size of the normal groups (1/50 is about 1.1°).
+	normalGroupPrecision = 50
+
+	// contactDistance is the max distance in mm of a point to the bed to count as contact.
+	// Faces this close to the bed are printed directly onto it, so they don't need support even if they are not exactly flat.
+	contactDistance = 0.2
+)
+
+// vec is a simple 3d vector in millimeter used for the orientation calculations.
+type vec [3]float64
+
+func (v vec) sub(o vec) vec {
+	return vec{v[0] - o[0], v[1] - o[1], v[2] - o[2]}
+}
+
+func (v vec) dot(o vec) float64 {
+	return v[0]*o[0] + v[1]*o[1] + v[2]*o[2]
+}
+
+func (v vec) cross(o vec) vec {
+	return vec{
+		v[1]*o[2] - v[2]*o[1],
+		v[2]*o[0] - v[0]*o[2],
+		v[0]*o[1] - v[1]*o[0],
+	}
+}
+
+func (v vec) length() float64 {
+	return math.Sqrt(v.dot(v))
+}
+
+func (v vec) normalize() vec {
+	l := v.length()
+	if l == 0 {
+		return v
+	}
+	return vec{v[0] / l, v[1] / l, v[2] / l}
+}
+
+// orientationFace is a face prepared for the evaluation.
+type orientationFace struct {
+	points [3]vec
+	normal vec
+	area   float64
+}
+
+// Orientation is the result of LayFlat.
+type Orientation struct {
+	// Rotation rotates the model into the chosen orientation.
+	Rotation data.Matrix
+
+	// RotateX, RotateY and RotateZ describe the same rotation in degree.
+	// They are applied in the order x, y, z (the same as data.TransformOptions).
+	RotateX, RotateY, RotateZ float64
+
+	// ContactArea is the area touching the bed in mm².
+	ContactArea float64
+
+	// OverhangArea is the area which needs support in mm².
+	OverhangArea float64
+}
+
+// LayFlat finds the rotation which maximizes the area of the model touching the bed
+// and minimizes the area of overhangs steeper than the thresholdAngle (see data.SupportOptions).
+// If no rotation is better than the current orientation, the identity is returned.
+func LayFlat(m data.Model, thresholdAngle int) Orientation {
+	faces := make([]orientationFace, 0, m.FaceCount())
+
+	type normalGroup struct {
+		normal vec
+		area   float64
+	}
+	groups := map[[3]int]*normalGroup{}
+
+	for i := 0; i < m.FaceCount(); i++ {
+		var f orientationFace
+		for j, p := range m.Face(i).Points() {
+			f.points[j] = vec{float64(p.X().ToMillimeter()), float64(p.Y().ToMillimeter()), float64(p.Z().ToMillimeter())}
+		}
+
+		n := f.points[1].sub(f.points[0]).cross(f.points[2].sub(f.points[0]))
+		f.area = n.length() / 2
+		if f.area == 0 {
+			continue
+		}
+		f.normal = n.normalize()
+		faces = append(faces, f)
+
+		key := [3]int{
+			int(math.Round(f.normal[0] * normalGroupPrecision)),
+			int(math.Round(f.normal[1] * normalGroupPrecision)),
+			int(math.Round(f.normal[2] * normalGroupPrecision)),
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &normalGroup{}
+			groups[key] = group
+		}
+		group.area += f.area
+		group.normal = vec{
+			group.normal[0] + f.normal[0]*f.area,
+			group.normal[1] + f.normal[1]*f.area,
+			group.normal[2] + f.normal[2]*f.area,
+		}
+	}
+
+	sorted := make([]*normalGroup, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].area != sorted[j].area {
+			return sorted[i].area > sorted[j].area
+		}
+		// Make the order deterministic for equal areas.
+		return sorted[i].normal.normalize()[2] < sorted[j].normal.normalize()[2]
+	})
+	if len(sorted) > orientationCandidates {
+		sorted = sorted[:orientationCandidates]
+	}
+
+	// The current orientation is always the first candidate so that it wins on equal scores.
+	overhangCos := math.Cos(data.ToRadians(90 - float64(thresholdAngle)))
+	down := vec{0, 0, -1}
+	best := Orientation{Rotation: data.IdentityMatrix()}
+	best.ContactArea, best.OverhangArea = evaluateOrientation(faces, down, overhangCos)
+
+	for _, group := range sorted {
+		candidate := group.normal.normalize()
+		contact, overhang := evaluateOrientation(faces, candidate, overhangCos)
+		if contact-overhang > best.ContactArea-best.OverhangArea {
+			best = Orientation{
+				Rotation:     rotationBetween(candidate, down),
+				ContactArea:  contact,
+				OverhangArea: overhang,
+			}
+		}
+	}
+
+	best.RotateX, best.RotateY, best.RotateZ = eulerAngles(best.Rotation)
+	return best
+}
+
+// evaluateOrientation calculates the contact and the overhang area if the model is rotated
+// so that the down vector points to -z.
+func evaluateOrientation(faces []orientationFace, down vec, overhangCos float64) (contact float64, overhang float64) {
+	// The bed is at the point which is the farthest in the down direction.
+	bed := math.Inf(-1)
+	for _, f := range faces {
+		for _, p := range f.points {
+			bed = math.Max(bed, p.dot(down))
+		}
+	}
+
+	for _, f := range faces {
+		cos := f.normal.dot(down)
+		if cos < overhangCos {
+			continue
+		}
+
+		onBed := true
+		for _, p := range f.points {
+			if bed-p.dot(down) > contactDistance {
+				onBed = false
+				break
+			}
+		}
+
+		if onBed {
+			contact += f.area
+		} else {
+			overhang += f.area
+		}
+	}
+
+	return contact, overhang
+}
+
+// rotationBetween returns the rotation which rotates the unit vector from onto the unit vector to.
+func rotationBetween(from, to vec) data.Matrix {
+	axis := from.cross(to)
+	sin := axis.length()
+	cos := from.dot(to)
+
+	if sin < 1e-9 {
+		if cos > 0 {
+			return data.IdentityMatrix()
+		}
+
+		// The vectors are opposite, so rotate by 180° around any perpendicular axis.
+		axis = from.cross(vec{1, 0, 0})
+		if axis.length() < 1e-9 {
+			axis = from.cross(vec{0, 1, 0})
+		}
+		sin = 0
+		cos = -1
+	}
+
+	// Rodrigues' rotation formula.
+	k := axis.normalize()
+	t := 1 - cos
+	return data.Matrix{
+		{t*k[0]*k[0] + cos, t*k[0]*k[1] - sin*k[2], t*k[0]*k[2] + sin*k[1], 0},
+		{t*k[0]*k[1] + sin*k[2], t*k[1]*k[1] + cos, t*k[1]*k[2] - sin*k[0], 0},
+		{t*k[0]*k[2] - sin*k[1], t*k[1]*k[2] + sin*k[0], t*k[2]*k[2] + cos, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// eulerAngles converts the rotation matrix into rotations around x, y and z in degree
+// which result in the same rotation if applied in the order x, y, z.
+func eulerAngles(m data.Matrix) (x, y, z float64) {
+	toDegree := func(radians float64) float64 {
+		return radians * 180 / math.Pi
+	}
+
+	sy := -m[2][0]
+	if sy > 1 {
+		sy = 1
+	} else if sy < -1 {
+		sy = -1
+	}
+	y = math.Asin(sy)
+
+	if math.Abs(sy) < 1-1e-9 {
+		x = math.Atan2(m[2][1], m[2][2])
+		z = math.Atan2(m[1][0], m[0][0])
+	} else {
+		// Gimbal lock: the x and z rotations are around the same axis.
+		x = math.Atan2(-m[1][2], m[1][1])
+		z = 0
+	}
+
+	return toDegree(x), toDegree(y), toDegree(z)
+}
@@ -0,0 +1,59 @@
+package transformer_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/transformer"
+	"github.com/aligator/goslice/util/test"
+)
+
+func TestLayFlat(t *testing.T) {
+	cube := data.NewBasicModel(test.CubeFaces(data.NewMicroVec3(0, 0, 0), 10000))
+	var tests = map[string]data.TransformOptions{
+		"already flat":    {Scale: 1},
+		"rotated x":       {Scale: 1, RotateX: 30},
+		"rotated x and y": {Scale: 1, RotateX: 30, RotateY: 50},
+		"upside down":     {Scale: 1, RotateX: 180, RotateZ: 20},
+	}
+
+	for name, options := range tests {
+		t.Log(name)
+
+		model := transformer.Transform(cube, transformer.Matrix(options))
+		orientation := transformer.LayFlat(model, 60)
+
+		test.Assert(t, math.Abs(orientation.ContactArea-100) < 0.5, "the bottom of the cube should lie on the bed, contact area: %v", orientation.ContactArea)
+		test.Equals(t, 0.0, orientation.OverhangArea)
+
+		// Applying the rotation should result in a model which lies flat on the bed.
+		flat := transformer.Transform(model, orientation.Rotation)
+		test.Assert(t, math.Abs(float64(flat.Max().Z()-flat.Min().Z())-10000) < 10, "the cube should be 10 mm high after lay flat, height: %v", flat.Max().Z()-flat.Min().Z())
+	}
+}
+
+func TestLayFlatKeepsFlatModel(t *testing.T) {
+	cube := data.NewBasicModel(test.CubeFaces(data.NewMicroVec3(0, 0, 0), 10000))
+	orientation := transformer.LayFlat(cube, 60)
+	test.Equals(t, data.IdentityMatrix(), orientation.Rotation)
+}
+
+func TestLayFlatAngles(t *testing.T) {
+	cube := data.NewBasicModel(test.CubeFaces(data.NewMicroVec3(0, 0, 0), 10000))
+	model := transformer.Transform(cube, transformer.Matrix(data.TransformOptions{Scale: 1, RotateX: 20, RotateY: 35, RotateZ: 10}))
+	orientation := transformer.LayFlat(model, 60)
+
+	// The angles have to describe the same rotation as the matrix.
+	matrix := transformer.Matrix(data.TransformOptions{
+		Scale:   1,
+		RotateX: orientation.RotateX,
+		RotateY: orientation.RotateY,
+		RotateZ: orientation.RotateZ,
+	})
+	for i := range matrix {
+		for j := range matrix[i] {
+			test.Assert(t, math.Abs(matrix[i][j]-orientation.Rotation[i][j]) < 1e-9, "the angles should result in the same matrix: %v != %v", matrix, orientation.Rotation)
+		}
+	}
+}
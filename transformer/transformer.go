@@ -0,0 +1,116 @@
+// Package transformer provides the built in model transformer.
+//
+// It applies an affine transformation (scale, mirror and rotation) to all faces of the model
+// before it gets optimized. If enabled, it then rotates the model so that it lies flat on the bed (see LayFlat).
+// Afterwards the model is dropped onto the bed, so that its lowest point is at z = 0.
+
+package transformer
+
+import (
+	"math"
+
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/handler"
+)
+
+type transformer struct {
+	options *data.Options
+}
+
+// NewTransformer provides a model transformer which uses the options.Transform.
+func NewTransformer(options *data.Options) handler.ModelTransformer {
+	return &transformer{
+		options: options,
+	}
+}
+
+func (t transformer) Transform(m data.Model) (data.Model, error) {
+	matrix := Matrix(t.options.Transform)
+	if matrix != data.IdentityMatrix() {
+		o := t.options.Transform
+		t.options.GoSlice.Logger.Printf("Transform model: scale %v, mirror x %v y %v z %v, rotate x %v° y %v° z %v°\n", o.Scale, o.MirrorX, o.MirrorY, o.MirrorZ, o.RotateX, o.RotateY, o.RotateZ)
+		m = Transform(m, matrix)
+	}
+
+	if t.options.Transform.LayFlat {
+		orientation := LayFlat(m, t.options.Print.Support.ThresholdAngle)
+		t.options.GoSlice.Logger.Printf("Lay flat: rotate x %.2f° y %.2f° z %.2f° (contact area %.2f mm², overhang area %.2f mm²)\n", orientation.RotateX, orientation.RotateY, orientation.RotateZ, orientation.ContactArea, orientation.OverhangArea)
+		if orientation.Rotation != data.IdentityMatrix() {
+			m = Transform(m, orientation.Rotation)
+		}
+	}
+
+	return m, nil
+}
+
+// Matrix composes the transformation matrix out of the options.
+// The order is scale, mirror, rotate x, rotate y and then rotate z.
+func Matrix(options data.TransformOptions) data.Matrix {
+	mirror := func(enabled bool) float64 {
+		if enabled {
+			return -1
+		}
+		return 1
+	}
+
+	scale := options.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	matrix := data.ScaleMatrix(scale*mirror(options.MirrorX), scale*mirror(options.MirrorY), scale*mirror(options.MirrorZ))
+
+	if options.RotateX != 0 {
+		matrix = data.RotationXMatrix(options.RotateX).Mul(matrix)
+	}
+	if options.RotateY != 0 {
+		matrix = data.RotationYMatrix(options.RotateY).Mul(matrix)
+	}
+	if options.RotateZ != 0 {
+		matrix = data.RotationZMatrix(options.RotateZ).Mul(matrix)
+	}
+
+	return matrix
+}
+
+// Transform applies the matrix to all faces of the model and drops it onto the bed (min z = 0).
+// If the matrix mirrors the model, the order of the points is reversed to keep the faces facing outwards.
+func Transform(m data.Model, matrix data.Matrix) data.Model {
+	mirrors := matrix.Determinant() < 0
+
+	faces := make([]data.Face, m.FaceCount())
+	minZ := data.Micrometer(math.MaxInt64)
+	for i := range faces {
+		var points [3]data.MicroVec3
+		for j, p := range m.Face(i).Points() {
+			x, y, z := matrix.Apply(float64(p.X()), float64(p.Y()), float64(p.Z()))
+			points[j] = data.NewMicroVec3(
+				data.Micrometer(math.Round(x)),
+				data.Micrometer(math.Round(y)),
+				data.Micrometer(math.Round(z)),
+			)
+
+			if points[j].Z() < minZ {
+				minZ = points[j].Z()
+			}
+		}
+
+		if mirrors {
+			points[1], points[2] = points[2], points[1]
+		}
+
+		faces[i] = data.NewBasicFace(points)
+	}
+
+	// Drop the model onto the bed.
+	drop := data.NewMicroVec3(0, 0, minZ)
+	for i, face := range faces {
+		points := face.Points()
+		for j := range points {
+			points[j] = points[j].Sub(drop)
+		}
+		faces[i] = data.NewBasicFace(points)
+	}
+
+	return data.NewBasicModel(faces)
+}
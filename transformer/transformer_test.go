@@ -0,0 +1,80 @@
+package transformer_test
+
+import (
+	"testing"
+
+	"github.com/aligator/goslice/data"
+	"github.com/aligator/goslice/transformer"
+	"github.com/aligator/goslice/util/test"
+)
+
+// testModel is a single face with a size of 10 x 20 x 30 mm which does not touch the bed.
+func testModel() data.Model {
+	return data.NewBasicModel([]data.Face{
+		data.NewBasicFace([3]data.MicroVec3{
+			data.NewMicroVec3(0, 0, 5000),
+			data.NewMicroVec3(10000, 0, 5000),
+			data.NewMicroVec3(0, 20000, 35000),
+		}),
+	})
+}
+
+func TestTransform(t *testing.T) {
+	var tests = map[string]struct {
+		options  data.TransformOptions
+		expected [3]data.MicroVec3
+	}{
+		"drop only": {
+			options: data.TransformOptions{Scale: 1},
+			expected: [3]data.MicroVec3{
+				data.NewMicroVec3(0, 0, 0),
+				data.NewMicroVec3(10000, 0, 0),
+				data.NewMicroVec3(0, 20000, 30000),
+			},
+		},
+		"scale": {
+			options: data.TransformOptions{Scale: 0.5},
+			expected: [3]data.MicroVec3{
+				data.NewMicroVec3(0, 0, 0),
+				data.NewMicroVec3(5000, 0, 0),
+				data.NewMicroVec3(0, 10000, 15000),
+			},
+		},
+		"rotate x": {
+			options: data.TransformOptions{Scale: 1, RotateX: 90},
+			expected: [3]data.MicroVec3{
+				data.NewMicroVec3(0, -5000, 0),
+				data.NewMicroVec3(10000, -5000, 0),
+				data.NewMicroVec3(0, -35000, 20000),
+			},
+		},
+		"mirror x reverses the winding": {
+			options: data.TransformOptions{Scale: 1, MirrorX: true},
+			expected: [3]data.MicroVec3{
+				data.NewMicroVec3(0, 0, 0),
+				data.NewMicroVec3(0, 20000, 30000),
+				data.NewMicroVec3(-10000, 0, 0),
+			},
+		},
+	}
+
+	for name, testCase := range tests {
+		t.Log(name)
+
+		model := transformer.Transform(testModel(), transformer.Matrix(testCase.options))
+		points := model.Face(0).Points()
+		for i := range points {
+			test.Equals(t, testCase.expected[i].String(), points[i].String())
+		}
+		test.Equals(t, data.Micrometer(0), model.Min().Z())
+	}
+}
+
+func TestTransformerIdentity(t *testing.T) {
+	options := data.DefaultOptions()
+	model := testModel()
+
+	result, err := transformer.NewTransformer(&options).Transform(model)
+	test.Ok(t, err)
+	test.Assert(t, result == model, "the model should not be changed without any transformation")
+}
@@ -0,0 +1,84 @@
+// Package parallel contains a simple worker pool to process independent items concurrently.
+
+package parallel
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Workers returns the amount of goroutines to use for the given concurrency option.
+// A concurrency of 0 or less means one goroutine per cpu.
+func Workers(concurrency int) int {
+	if concurrency <= 0 {
+		return runtime.NumCPU()
+	}
+	return concurrency
+}
+
+// ForEach calls fn for each index from 0 to count-1 using up to Workers(concurrency) goroutines.
+// The indices are started in ascending order but may finish in any order,
+// so fn must only write to data which belongs to its index.
+//
+// After the first error no new indices are started.
+// If several calls fail, the error of the lowest index is returned to keep the result deterministic.
+// If the context gets cancelled, no new indices are started and the error of the context is returned.
+func ForEach(ctx context.Context, concurrency int, count int, fn func(i int) error) error {
+	workers := Workers(concurrency)
+	if workers > count {
+		workers = count
+	}
+
+	// Just run it directly if no concurrency is possible.
+	if workers <= 1 {
+		for i := 0; i < count; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, count)
+	indices := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if err := fn(i); err != nil {
+					errs[i] = err
+					cancel()
+				}
+			}
+		}()
+	}
+
+	// Check for cancellation before each index so that the caller gets the context error.
+	var ctxErr error
+	for i := 0; i < count; i++ {
+		if ctxErr = ctx.Err(); ctxErr != nil {
+			break
+		}
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return ctxErr
+}
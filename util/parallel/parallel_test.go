@@ -0,0 +1,62 @@
+package parallel_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aligator/goslice/util/parallel"
+	"github.com/aligator/goslice/util/test"
+)
+
+func TestForEach(t *testing.T) {
+	for _, concurrency := range []int{0, 1, 4, 200} {
+		t.Log("concurrency", concurrency)
+
+		results := make([]int, 100)
+		err := parallel.ForEach(context.Background(), concurrency, len(results), func(i int) error {
+			results[i] = i * 2
+			return nil
+		})
+		test.Ok(t, err)
+
+		for i, result := range results {
+			test.Equals(t, i*2, result)
+		}
+	}
+}
+
+func TestForEachError(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		t.Log("concurrency", concurrency)
+
+		err := parallel.ForEach(context.Background(), concurrency, 100, func(i int) error {
+			if i == 10 || i == 11 {
+				return fmt.Errorf("error %d", i)
+			}
+			return nil
+		})
+		test.Equals(t, "error 10", err.Error())
+	}
+}
+
+func TestForEachCancelled(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		t.Log("concurrency", concurrency)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls int32
+		err := parallel.ForEach(ctx, concurrency, 1000, func(i int) error {
+			if atomic.AddInt32(&calls, 1) == 10 {
+				cancel()
+			}
+			return nil
+		})
+		cancel()
+
+		test.Assert(t, errors.Is(err, context.Canceled), "the context error was expected but got %v", err)
+		test.Assert(t, atomic.LoadInt32(&calls) < 1000, "not all indices should be processed")
+	}
+}
@@ -0,0 +1,29 @@
+package test
+
+import "github.com/aligator/goslice/data"
+
+// CubeFaces returns the faces of a closed cube with the given size which starts at the given min corner.
+// The first two faces are the bottom and all faces face outwards.
+func CubeFaces(min data.MicroVec3, size data.Micrometer) []data.Face {
+	p := func(x, y, z data.Micrometer) data.MicroVec3 {
+		return min.Add(data.NewMicroVec3(x*size, y*size, z*size))
+	}
+	face := func(a, b, c data.MicroVec3) data.Face {
+		return data.NewBasicFace([3]data.MicroVec3{a, b, c})
+	}
+
+	return []data.Face{
+		face(p(0, 0, 0), p(1, 1, 0), p(1, 0, 0)),
+		face(p(0, 0, 0), p(0, 1, 0), p(1, 1, 0)),
+		face(p(0, 0, 1), p(1, 0, 1), p(1, 1, 1)),
+		face(p(0, 0, 1), p(1, 1, 1), p(0, 1, 1)),
+		face(p(0, 0, 0), p(1, 0, 0), p(1, 0, 1)),
+		face(p(0, 0, 0), p(1, 0, 1), p(0, 0, 1)),
+		face(p(0, 1, 0), p(1, 1, 1), p(1, 1, 0)),
+		face(p(0, 1, 0), p(0, 1, 1), p(1, 1, 1)),
+		face(p(0, 0, 0), p(0, 0, 1), p(0, 1, 1)),
+		face(p(0, 0, 0), p(0, 1, 1), p(0, 1, 0)),
+		face(p(1, 0, 0), p(1, 1, 0), p(1, 1, 1)),
+		face(p(1, 0, 0), p(1, 1, 1), p(1, 0, 1)),
+	}
+}
@@ -1,25 +1,37 @@
 package writer
 
 import (
-	"github.com/aligator/goslice/handler"
+	"io"
 	"os"
+
+	"github.com/aligator/goslice/handler"
 )
 
 type writer struct{}
 
 // Writer can write gcode to a file.
+// It also implements handler.GCodeFileCreator to write the gcode while it is generated
+// and handler.GCodeStreamWriter to write into any io.Writer.
 func Writer() handler.GCodeWriter {
 	return &writer{}
 }
 
+func (w writer) Create(filename string) (io.WriteCloser, error) {
+	return os.Create(filename)
+}
+
 func (w writer) Write(gcode string, filename string) error {
-	buf, err := os.Create(filename)
+	buf, err := w.Create(filename)
 	if err != nil {
 		return err
 	}
 
 	defer buf.Close()
 
-	_, err = buf.WriteString(gcode)
+	return w.WriteStream(gcode, buf)
+}
+
+func (w writer) WriteStream(gcode string, out io.Writer) error {
+	_, err := io.WriteString(out, gcode)
 	return err
 }